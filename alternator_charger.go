@@ -0,0 +1,144 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+)
+
+// Ecoflow documentation: https://developer-eu.ecoflow.com/us/document/alternatorcharger
+// 800W Alternator Charger is configured by the "cfgSpCharger*" parameters of the documented cfg schema (see setCfgParameter),
+// the state is reported by the same parameters without the "cfg" prefix, e.g. "spChargerChgMode".
+// It charges the power station (or the Power Kit) from the car battery, charges the car battery from the power station
+// (reverse charge) or keeps the car battery charged (maintenance).
+// There is no start voltage setting: the documented schema doesn't have a parameter for it
+
+// AlternatorChargerMode is the operating mode of the charger, spChargerChgMode
+type AlternatorChargerMode int
+
+const (
+	AlternatorChargerModeIdle          AlternatorChargerMode = 0
+	AlternatorChargerModeCharge        AlternatorChargerMode = 1 // the power station is charged from the alternator
+	AlternatorChargerModeMaintenance   AlternatorChargerMode = 2 // the car battery is kept charged by the power station
+	AlternatorChargerModeReverseCharge AlternatorChargerMode = 3 // the car battery is charged by the power station
+)
+
+const (
+	alternatorMinCurrentLimit = 4
+	alternatorMaxCurrentLimit = 70
+	alternatorMinPowerLimit   = 100
+	alternatorMaxPowerLimit   = 800
+)
+
+type AlternatorCharger struct {
+	c  *Client
+	sn string
+}
+
+func (s *AlternatorCharger) GetSn() string {
+	return s.sn
+}
+
+// AlternatorChargerStatus is the state of an Alternator Charger
+type AlternatorChargerStatus struct {
+	Mode    AlternatorChargerMode
+	Enabled bool
+	// CarBatteryVoltage is the voltage of the car (starter) battery, V
+	CarBatteryVoltage float64
+	// Watts is the charging power: to the power station in the charge mode, to the car battery in the reverse charge and maintenance modes
+	Watts float64
+	// StationSoc is the battery level of the connected power station, %
+	StationSoc float64
+	// CurrentLimit is the max charging current of the car battery, A
+	CurrentLimit int
+	// PowerLimit is the max charging power, W
+	PowerLimit  int
+	Temperature float64
+	// ErrorCode is 0 when there are no errors
+	ErrorCode int
+}
+
+// GetStatus returns the typed state of the alternator charger
+func (s *AlternatorCharger) GetStatus(ctx context.Context) (*AlternatorChargerStatus, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeAlternatorChargerStatus(params)
+}
+
+// DecodeAlternatorChargerStatus decodes the Alternator Charger state from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// Missing parameters have zero values, an error is returned only if the charger mode is not found
+func DecodeAlternatorChargerStatus(params map[string]interface{}) (*AlternatorChargerStatus, error) {
+	mode, ok := NumericValue(params["spChargerChgMode"])
+	if !ok {
+		return nil, errors.New("spChargerChgMode parameter is not found")
+	}
+	value := func(key string) float64 {
+		v, _ := NumericValue(params[key])
+		return v
+	}
+	return &AlternatorChargerStatus{
+		Mode:              AlternatorChargerMode(mode),
+		Enabled:           value("spChargerChgOpen") == 1,
+		CarBatteryVoltage: value("spChargerCarBattVol"),
+		Watts:             value("powGetDcp"),
+		StationSoc:        value("cmsBattSoc"),
+		CurrentLimit:      int(value("spChargerCarBattChgAmpLimit")),
+		PowerLimit:        int(value("spChargerChgPowLimit")),
+		Temperature:       value("temp"),
+		ErrorCode:         int(value("errCode")),
+	}, nil
+}
+
+// SetEnabled Starting or stopping the charging
+// { "sn":"F371ZE1AXH000001", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgSpChargerChgOpen":true } }
+func (s *AlternatorCharger) SetEnabled(ctx context.Context, enabled bool) (*CmdSetResponse, error) {
+	params := make(map[string]interface{})
+	params["cfgSpChargerChgOpen"] = enabled
+	return s.setParameter(ctx, params)
+}
+
+// SetMode Setting the operating mode (1: charge, 2: maintenance, 3: reverse charge)
+// { "sn":"F371ZE1AXH000001", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgSpChargerChgMode":1 } }
+func (s *AlternatorCharger) SetMode(ctx context.Context, mode AlternatorChargerMode) (*CmdSetResponse, error) {
+	if mode < AlternatorChargerModeCharge || mode > AlternatorChargerModeReverseCharge {
+		return nil, errors.New("cfgSpChargerChgMode out of range. Range 1:3")
+	}
+	params := make(map[string]interface{})
+	params["cfgSpChargerChgMode"] = mode
+	return s.setParameter(ctx, params)
+}
+
+// SetCurrentLimit Setting the max charging current of the car battery, A (range 4:70)
+// { "sn":"F371ZE1AXH000001", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgSpChargerCarBattChgAmpLimit":50 } }
+func (s *AlternatorCharger) SetCurrentLimit(ctx context.Context, amps int) (*CmdSetResponse, error) {
+	if amps < alternatorMinCurrentLimit || amps > alternatorMaxCurrentLimit {
+		return nil, errors.New("cfgSpChargerCarBattChgAmpLimit out of range. Range 4:70")
+	}
+	params := make(map[string]interface{})
+	params["cfgSpChargerCarBattChgAmpLimit"] = amps
+	return s.setParameter(ctx, params)
+}
+
+// SetPowerLimit Setting the max charging power, W (range 100:800)
+// { "sn":"F371ZE1AXH000001", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgSpChargerChgPowLimit":600 } }
+func (s *AlternatorCharger) SetPowerLimit(ctx context.Context, watts int) (*CmdSetResponse, error) {
+	if watts < alternatorMinPowerLimit || watts > alternatorMaxPowerLimit {
+		return nil, errors.New("cfgSpChargerChgPowLimit out of range. Range 100:800")
+	}
+	params := make(map[string]interface{})
+	params["cfgSpChargerChgPowLimit"] = watts
+	return s.setParameter(ctx, params)
+}
+
+func (s *AlternatorCharger) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
+	return s.c.GetDeviceParameters(ctx, s.sn, params)
+}
+
+func (s *AlternatorCharger) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
+	return s.c.GetDeviceAllParameters(ctx, s.sn)
+}
+
+func (s *AlternatorCharger) setParameter(ctx context.Context, params map[string]interface{}) (*CmdSetResponse, error) {
+	return s.c.setCfgParameter(ctx, s.sn, params)
+}
@@ -0,0 +1,78 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAlternatorChargerStatus(t *testing.T) {
+	client, _ := newTestClient(t, map[string]interface{}{
+		"spChargerChgMode":            1,
+		"spChargerChgOpen":            1,
+		"spChargerCarBattVol":         13.8,
+		"powGetDcp":                   640,
+		"cmsBattSoc":                  45,
+		"spChargerCarBattChgAmpLimit": 50,
+		"spChargerChgPowLimit":        800,
+		"temp":                        41,
+	})
+	status, err := client.GetAlternatorCharger("F371ZE1AXH000001").GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := AlternatorChargerStatus{Mode: AlternatorChargerModeCharge, Enabled: true, CarBatteryVoltage: 13.8, Watts: 640,
+		StationSoc: 45, CurrentLimit: 50, PowerLimit: 800, Temperature: 41}
+	if *status != expected {
+		t.Errorf("got %+v, expected %+v", *status, expected)
+	}
+}
+
+func TestAlternatorChargerSetters(t *testing.T) {
+	client, last := newTestClient(t, nil)
+	a := client.GetAlternatorCharger("F371ZE1AXH000001")
+
+	runSetterTests(t, last, []setterTest{
+		{
+			name:   "Enabled",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return a.SetEnabled(ctx, true) },
+			params: map[string]interface{}{"cfgSpChargerChgOpen": true},
+		},
+		{
+			name: "Reverse charge mode",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return a.SetMode(ctx, AlternatorChargerModeReverseCharge)
+			},
+			params: map[string]interface{}{"cfgSpChargerChgMode": float64(3)},
+		},
+		{
+			name:   "Current limit",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return a.SetCurrentLimit(ctx, 70) },
+			params: map[string]interface{}{"cfgSpChargerCarBattChgAmpLimit": float64(70)},
+		},
+		{
+			name:   "Power limit",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return a.SetPowerLimit(ctx, 100) },
+			params: map[string]interface{}{"cfgSpChargerChgPowLimit": float64(100)},
+		},
+		{
+			name: "Idle mode",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return a.SetMode(ctx, AlternatorChargerModeIdle) },
+		},
+		{
+			name: "Current limit out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return a.SetCurrentLimit(ctx, 2) },
+		},
+		{
+			name: "Power limit out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return a.SetPowerLimit(ctx, 900) },
+		},
+	})
+
+	// the settings are sent with the cfg header
+	if _, err := a.SetEnabled(context.Background(), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if (*last)["cmdFunc"] != float64(254) {
+		t.Errorf("unexpected request %v", *last)
+	}
+}
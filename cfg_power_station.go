@@ -0,0 +1,135 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// cfgPowerStation is the part of the "cfg*" protocol shared by Delta Pro 3, River 3 and Delta 3 series power stations.
+// It's embedded in DeltaPro3 and PowerStation3, the device specific settings are defined on those types
+type cfgPowerStation struct {
+	c  *Client
+	sn string
+	// acChargingMin and acChargingMax are the limits of cfgPlugInInfoAcInChgPowMax, W
+	acChargingMin int
+	acChargingMax int
+}
+
+func (s *cfgPowerStation) GetSn() string {
+	return s.sn
+}
+
+// SetDcOutput Setting the 12 V DC output switch
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgDc12vOutOpen":true } }
+func (s *cfgPowerStation) SetDcOutput(ctx context.Context, enabled bool) (*CmdSetResponse, error) {
+	params := make(map[string]interface{})
+	params["cfgDc12vOutOpen"] = enabled
+	return s.setParameter(ctx, params)
+}
+
+// SetXboost Setting the X-Boost switch
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgXboostEn":true } }
+func (s *cfgPowerStation) SetXboost(ctx context.Context, enabled bool) (*CmdSetResponse, error) {
+	params := make(map[string]interface{})
+	params["cfgXboostEn"] = enabled
+	return s.setParameter(ctx, params)
+}
+
+// SetMaxChargeSoc Setting the charge limit (range 50:100)
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgMaxChgSoc":100 } }
+func (s *cfgPowerStation) SetMaxChargeSoc(ctx context.Context, maxChgSoc int) (*CmdSetResponse, error) {
+	if maxChgSoc < 50 || maxChgSoc > 100 {
+		return nil, errors.New("cfgMaxChgSoc out of range. Range 50:100")
+	}
+	params := make(map[string]interface{})
+	params["cfgMaxChgSoc"] = maxChgSoc
+	return s.setParameter(ctx, params)
+}
+
+// SetMinDischargeSoc Setting the discharge limit (range 0:30)
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgMinDsgSoc":10 } }
+func (s *cfgPowerStation) SetMinDischargeSoc(ctx context.Context, minDsgSoc int) (*CmdSetResponse, error) {
+	if minDsgSoc < 0 || minDsgSoc > 30 {
+		return nil, errors.New("cfgMinDsgSoc out of range. Range 0:30")
+	}
+	params := make(map[string]interface{})
+	params["cfgMinDsgSoc"] = minDsgSoc
+	return s.setParameter(ctx, params)
+}
+
+// SetAcChargingWatts Setting the max AC charging power, W. The range depends on the model, see AcChargingRange
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgPlugInInfoAcInChgPowMax":1500 } }
+func (s *cfgPowerStation) SetAcChargingWatts(ctx context.Context, watts int) (*CmdSetResponse, error) {
+	if watts < s.acChargingMin || watts > s.acChargingMax {
+		return nil, fmt.Errorf("cfgPlugInInfoAcInChgPowMax out of range. Range %d:%d", s.acChargingMin, s.acChargingMax)
+	}
+	params := make(map[string]interface{})
+	params["cfgPlugInInfoAcInChgPowMax"] = watts
+	return s.setParameter(ctx, params)
+}
+
+// AcChargingRange returns the range accepted by SetAcChargingWatts, W
+func (s *cfgPowerStation) AcChargingRange() (minWatts, maxWatts int) {
+	return s.acChargingMin, s.acChargingMax
+}
+
+// SetBackupReserve Setting the backup reserve (energyBackupStartSoc range 5:100)
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true,
+// "params":{ "cfgEnergyBackup":{ "energyBackupEn":true, "energyBackupStartSoc":50 } } }
+func (s *cfgPowerStation) SetBackupReserve(ctx context.Context, enabled bool, soc int) (*CmdSetResponse, error) {
+	if soc < 5 || soc > 100 {
+		return nil, errors.New("energyBackupStartSoc out of range. Range 5:100")
+	}
+	backup := make(map[string]interface{})
+	backup["energyBackupEn"] = enabled
+	backup["energyBackupStartSoc"] = soc
+	params := make(map[string]interface{})
+	params["cfgEnergyBackup"] = backup
+	return s.setParameter(ctx, params)
+}
+
+// SetScreenTimeout Setting the screen timeout, seconds (0: never off)
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgScreenOffTime":60 } }
+func (s *cfgPowerStation) SetScreenTimeout(ctx context.Context, seconds int) (*CmdSetResponse, error) {
+	if seconds < 0 {
+		return nil, errors.New("cfgScreenOffTime must be positive")
+	}
+	params := make(map[string]interface{})
+	params["cfgScreenOffTime"] = seconds
+	return s.setParameter(ctx, params)
+}
+
+// SetAcStandByTime Setting the AC standby time, minutes (0: never)
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgAcStandbyTime":720 } }
+func (s *cfgPowerStation) SetAcStandByTime(ctx context.Context, minutes int) (*CmdSetResponse, error) {
+	if minutes < 0 {
+		return nil, errors.New("cfgAcStandbyTime must be positive")
+	}
+	params := make(map[string]interface{})
+	params["cfgAcStandbyTime"] = minutes
+	return s.setParameter(ctx, params)
+}
+
+// SetDeviceStandByTime Setting the device standby time, minutes (0: never)
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgDevStandbyTime":120 } }
+func (s *cfgPowerStation) SetDeviceStandByTime(ctx context.Context, minutes int) (*CmdSetResponse, error) {
+	if minutes < 0 {
+		return nil, errors.New("cfgDevStandbyTime must be positive")
+	}
+	params := make(map[string]interface{})
+	params["cfgDevStandbyTime"] = minutes
+	return s.setParameter(ctx, params)
+}
+
+func (s *cfgPowerStation) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
+	return s.c.GetDeviceParameters(ctx, s.sn, params)
+}
+
+func (s *cfgPowerStation) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
+	return s.c.GetDeviceAllParameters(ctx, s.sn)
+}
+
+func (s *cfgPowerStation) setParameter(ctx context.Context, params map[string]interface{}) (*CmdSetResponse, error) {
+	return s.c.setCfgParameter(ctx, s.sn, params)
+}
@@ -0,0 +1,40 @@
+package ecoflow
+
+import (
+	"context"
+)
+
+// The newer devices (Delta Pro 3, River 3, Delta 3, PowerOcean, Alternator Charger) are configured by "cfg*" parameters,
+// all the set commands have the same header:
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgBeepEn":false } }
+const (
+	cfgSetCmdId   = 17
+	cfgSetCmdFunc = 254
+	cfgSetDir     = 1
+	cfgSetDest    = 2
+)
+
+type cfgSetRequest struct {
+	Sn      string                 `json:"sn"`
+	CmdId   int                    `json:"cmdId"`
+	DirDest int                    `json:"dirDest"`
+	DirSrc  int                    `json:"dirSrc"`
+	CmdFunc int                    `json:"cmdFunc"`
+	Dest    int                    `json:"dest"`
+	NeedAck bool                   `json:"needAck"`
+	Params  map[string]interface{} `json:"params"`
+}
+
+// setCfgParameter sends the "cfg*" parameters to the device
+func (c *Client) setCfgParameter(ctx context.Context, sn string, params map[string]interface{}) (*CmdSetResponse, error) {
+	return c.setCfgFuncParameter(ctx, sn, cfgSetCmdFunc, params)
+}
+
+// setCfgFuncParameter sends the parameters with the cfg header and the given cmdFunc (e.g. Smart Home Panel 2 uses cmdFunc 12)
+func (c *Client) setCfgFuncParameter(ctx context.Context, sn string, cmdFunc int, params map[string]interface{}) (*CmdSetResponse, error) {
+	return c.SendCommand(ctx, Command{
+		Sn:      sn,
+		CmdFunc: cmdFunc,
+		Params:  params,
+	})
+}
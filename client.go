@@ -46,6 +46,7 @@ type Client struct {
 	accessToken string
 	secretToken string
 	baseUrl     string
+	dryRun      bool // the set commands are not sent, see WithDryRun
 }
 
 // NewEcoflowClient with default http client
@@ -89,6 +90,28 @@ func (c *Client) GetPowerStationPro(sn string) *PowerStationPro {
 	}
 }
 
+func (c *Client) GetPowerStation3(sn string) *PowerStation3 {
+	return newPowerStation3(c, sn)
+}
+
+func (c *Client) GetDeltaPro3(sn string) *DeltaPro3 {
+	return newDeltaPro3(c, sn)
+}
+
+func (c *Client) GetDeltaProUltra(sn string) *DeltaProUltra {
+	return &DeltaProUltra{
+		c:  c,
+		sn: sn,
+	}
+}
+
+func (c *Client) GetPowerOcean(sn string) *PowerOcean {
+	return &PowerOcean{
+		c:  c,
+		sn: sn,
+	}
+}
+
 func (c *Client) GetPowerStreamMicroInverter(sn string) *PowerStreamMicroInverter {
 	return &PowerStreamMicroInverter{
 		c:  c,
@@ -103,6 +126,13 @@ func (c *Client) GetSmartHomePanel(sn string) *SmartHomePanel {
 	}
 }
 
+func (c *Client) GetSmartHomePanel2(sn string) *SmartHomePanel2 {
+	return &SmartHomePanel2{
+		c:  c,
+		sn: sn,
+	}
+}
+
 func (c *Client) GetSmartPlug(sn string) *SmartPlug {
 	return &SmartPlug{
 		c:  c,
@@ -110,20 +140,73 @@ func (c *Client) GetSmartPlug(sn string) *SmartPlug {
 	}
 }
 
+// GetWaveAirConditioner returns the air conditioner with the capabilities of Wave 2 (the documented model)
 func (c *Client) GetWaveAirConditioner(sn string) *WaveAirConditioner {
 	return &WaveAirConditioner{
-		c:  c,
-		sn: sn,
+		c:    c,
+		sn:   sn,
+		caps: waveCapabilities[WaveModelWave2],
 	}
 }
 
+// GetWaveAirConditionerModel returns the air conditioner that rejects the modes and temperatures unsupported by the model
+func (c *Client) GetWaveAirConditionerModel(sn string, model WaveModel) (*WaveAirConditioner, error) {
+	caps, ok := GetWaveCapabilities(model)
+	if !ok {
+		return nil, fmt.Errorf("unknown Wave model %q", model)
+	}
+	return &WaveAirConditioner{
+		c:    c,
+		sn:   sn,
+		caps: caps,
+	}, nil
+}
+
+// GetGlacier returns the fridge with the capabilities of Glacier (the documented model)
 func (c *Client) GetGlacier(sn string) *Glacier {
 	return &Glacier{
+		c:    c,
+		sn:   sn,
+		caps: glacierCapabilities[GlacierModelGlacier],
+	}
+}
+
+// GetGlacierModel returns the fridge that rejects the commands and temperatures unsupported by the model
+func (c *Client) GetGlacierModel(sn string, model GlacierModel) (*Glacier, error) {
+	caps, ok := GetGlacierCapabilities(model)
+	if !ok {
+		return nil, fmt.Errorf("unknown Glacier model %q", model)
+	}
+	return &Glacier{
+		c:    c,
+		sn:   sn,
+		caps: caps,
+	}, nil
+}
+
+func (c *Client) GetSmartGenerator(sn string) *SmartGenerator {
+	return &SmartGenerator{
+		c:  c,
+		sn: sn,
+	}
+}
+
+func (c *Client) GetAlternatorCharger(sn string) *AlternatorCharger {
+	return &AlternatorCharger{
 		c:  c,
 		sn: sn,
 	}
 }
 
+// GetGenericDevice returns the device described by the schema, see LoadDeviceSchema
+func (c *Client) GetGenericDevice(sn string, schema *DeviceSchema) *GenericDevice {
+	return &GenericDevice{
+		c:      c,
+		sn:     sn,
+		schema: schema,
+	}
+}
+
 func (c *Client) GetPowerKit(sn string, moduleSn string) *PowerKit {
 	return &PowerKit{
 		c:        c,
@@ -148,8 +231,10 @@ type DeviceListResponse struct {
 }
 
 type DeviceInfo struct {
-	SN     string `json:"sn"`
-	Online int    `json:"online"`
+	SN          string `json:"sn"`
+	Online      int    `json:"online"`
+	ProductName string `json:"productName,omitempty"`
+	DeviceName  string `json:"deviceName,omitempty"`
 }
 
 // GetDeviceList executes a request to get the list of devises linked to the user account. Shared devices are not included
@@ -185,6 +270,8 @@ type CmdSetRequest struct {
 type CmdSetResponse struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	// DryRun are the signed requests that would have been sent by the call, it's set only in the dry-run mode
+	DryRun []*DryRunRequest `json:"-"`
 }
 
 func getParamsEnabled(enabled SettingSwitcher) map[string]interface{} {
@@ -200,6 +287,9 @@ func (c *Client) SetDeviceParameter(ctx context.Context, request map[string]inte
 	slog.Debug("SetDeviceParameter", "request", request)
 
 	r := NewHttpRequest(c.httpClient, "PUT", c.baseUrl+setDeviceFunctionUrl, request, c.accessToken, c.secretToken)
+	if c.isDryRun(ctx) {
+		return dryRunResponse(ctx, r)
+	}
 
 	response, err := r.Execute(ctx)
 	if err != nil {
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tess1o/go-ecoflow"
+)
+
+const namespace = "ecoflow"
+
+const quotaHelp = "Ecoflow device parameter, see the device documentation for the description and the unit"
+
+var (
+	quotaLabels  = []string{"sn", "device_type", "module"}
+	deviceOnline = prometheus.NewDesc(
+		namespace+"_device_online",
+		"Whether the device is connected to the Ecoflow cloud (1) or not (0)",
+		[]string{"sn", "device_type"}, nil,
+	)
+	deviceLastUpdate = prometheus.NewDesc(
+		namespace+"_device_last_update_timestamp_seconds",
+		"Unix time when the device parameters were received last time",
+		[]string{"sn", "device_type"}, nil,
+	)
+)
+
+type deviceState struct {
+	deviceType ecoflow.DeviceType
+	online     *bool
+	updated    time.Time
+	values     map[string]float64
+}
+
+// collector keeps the latest received values of the device parameters and exposes them as gauges.
+// Every numeric parameter becomes a metric named from the parameter field, the module is added as a label:
+// "bms_bmsStatus.inputWatts" of device R331XXX is exposed as ecoflow_input_watts{sn="R331XXX",device_type="power_station",module="bms_bmsStatus"}
+type collector struct {
+	mu      sync.RWMutex
+	filter  *ecoflow.KeyFilter
+	devices map[string]*deviceState
+}
+
+func newCollector(filter *ecoflow.KeyFilter) *collector {
+	return &collector{
+		filter:  filter,
+		devices: make(map[string]*deviceState),
+	}
+}
+
+func (c *collector) device(sn string, deviceType ecoflow.DeviceType) *deviceState {
+	d, ok := c.devices[sn]
+	if !ok {
+		d = &deviceState{deviceType: deviceType, values: make(map[string]float64)}
+		c.devices[sn] = d
+	}
+	if deviceType != ecoflow.DeviceTypeUnknown && deviceType != "" {
+		d.deviceType = deviceType
+	}
+	return d
+}
+
+// UpdateDevices sets the online status of the linked devices
+func (c *collector) UpdateDevices(devices []ecoflow.Device) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, d := range devices {
+		online := d.IsOnline()
+		c.device(d.SN, d.Type).online = &online
+	}
+}
+
+// Update stores the numeric parameters of the snapshot. Full snapshots (REST API) replace all previous values,
+// partial snapshots (MQTT) update only received parameters
+func (c *collector) Update(s ecoflow.DeviceSnapshot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	d := c.device(s.Sn, s.DeviceType)
+	if !s.Partial {
+		d.values = make(map[string]float64, len(s.Params))
+	}
+	for k, v := range s.Params {
+		if !c.filter.Match(k) {
+			continue
+		}
+		if f, ok := ecoflow.NumericValue(v); ok {
+			d.values[k] = f
+		}
+	}
+	d.updated = s.Timestamp
+}
+
+// Describe doesn't send any descriptors: the quota metrics depend on the received parameters, so the collector is unchecked
+func (c *collector) Describe(chan<- *prometheus.Desc) {
+}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for sn, d := range c.devices {
+		deviceType := string(d.deviceType)
+		if d.online != nil {
+			ch <- prometheus.MustNewConstMetric(deviceOnline, prometheus.GaugeValue, boolToFloat(*d.online), sn, deviceType)
+		}
+		if !d.updated.IsZero() {
+			ch <- prometheus.MustNewConstMetric(deviceLastUpdate, prometheus.GaugeValue, float64(d.updated.Unix()), sn, deviceType)
+		}
+
+		// keys are sorted, so if two keys have the same metric name and module, the first one always wins
+		keys := make([]string, 0, len(d.values))
+		for k := range d.values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		seen := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			module, field := ecoflow.SplitQuotaKey(k)
+			name := metricName(field)
+			if seen[name+"|"+module] {
+				continue
+			}
+			seen[name+"|"+module] = true
+			desc := prometheus.NewDesc(name, quotaHelp, quotaLabels, nil)
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, d.values[k], sn, deviceType, module)
+		}
+	}
+}
+
+// metricName converts a parameter field to a valid prometheus metric name: "pv1InputWatts" -> "ecoflow_pv1_input_watts"
+func metricName(field string) string {
+	var b strings.Builder
+	b.WriteString(namespace)
+	b.WriteByte('_')
+	prevLower := false
+	for _, r := range field {
+		switch {
+		case unicode.IsUpper(r) && r < unicode.MaxASCII:
+			if prevLower {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			prevLower = false
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+			prevLower = true
+		default:
+			b.WriteByte('_')
+			prevLower = false
+		}
+	}
+	return b.String()
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tess1o/go-ecoflow"
+)
+
+func TestMetricName(t *testing.T) {
+	tests := []struct {
+		field    string
+		expected string
+	}{
+		{"soc", "ecoflow_soc"},
+		{"inputWatts", "ecoflow_input_watts"},
+		{"pv1InputWatts", "ecoflow_pv1_input_watts"},
+		{"FastChgWatts", "ecoflow_fast_chg_watts"},
+		{"bmsStatus.temp", "ecoflow_bms_status_temp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if actual := metricName(tt.field); actual != tt.expected {
+				t.Errorf("metricName(%s) = %s; expected %s", tt.field, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCollector(t *testing.T) {
+	filter, err := ecoflow.NewKeyFilter(nil, []string{"pd.wifiVer"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := newCollector(filter)
+	c.UpdateDevices([]ecoflow.Device{{DeviceInfo: ecoflow.DeviceInfo{SN: "R331ZEB4ZEAL0528", Online: 1}, Type: ecoflow.DeviceTypePowerStation}})
+	c.Update(ecoflow.DeviceSnapshot{
+		Sn:         "R331ZEB4ZEAL0528",
+		DeviceType: ecoflow.DeviceTypePowerStation,
+		Timestamp:  time.Unix(1700000000, 0),
+		Params: map[string]interface{}{
+			"pd.soc":            float64(55),
+			"bms_bmsStatus.soc": float64(54),
+			"inv.outputWatts":   float64(120),
+			"pd.wifiVer":        float64(1),
+			"pd.model":          "Delta 2",
+		},
+	})
+	// MQTT update only changes the received values
+	c.Update(ecoflow.DeviceSnapshot{
+		Sn:         "R331ZEB4ZEAL0528",
+		DeviceType: ecoflow.DeviceTypePowerStation,
+		Timestamp:  time.Unix(1700000010, 0),
+		Partial:    true,
+		Params:     map[string]interface{}{"inv.outputWatts": float64(200)},
+	})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c)
+
+	expected := `
+# HELP ecoflow_device_online Whether the device is connected to the Ecoflow cloud (1) or not (0)
+# TYPE ecoflow_device_online gauge
+ecoflow_device_online{device_type="power_station",sn="R331ZEB4ZEAL0528"} 1
+# HELP ecoflow_output_watts Ecoflow device parameter, see the device documentation for the description and the unit
+# TYPE ecoflow_output_watts gauge
+ecoflow_output_watts{device_type="power_station",module="inv",sn="R331ZEB4ZEAL0528"} 200
+# HELP ecoflow_soc Ecoflow device parameter, see the device documentation for the description and the unit
+# TYPE ecoflow_soc gauge
+ecoflow_soc{device_type="power_station",module="bms_bmsStatus",sn="R331ZEB4ZEAL0528"} 54
+ecoflow_soc{device_type="power_station",module="pd",sn="R331ZEB4ZEAL0528"} 55
+`
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"ecoflow_device_online", "ecoflow_output_watts", "ecoflow_soc", "ecoflow_wifi_ver")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
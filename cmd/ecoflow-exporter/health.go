@@ -0,0 +1,39 @@
+package main
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// healthMetrics are the exporter's own metrics: Ecoflow REST API errors and latency
+type healthMetrics struct {
+	apiErrors  *prometheus.CounterVec
+	apiLatency *prometheus.HistogramVec
+}
+
+func newHealthMetrics() *healthMetrics {
+	return &healthMetrics{
+		apiErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "exporter",
+			Name:      "api_errors_total",
+			Help:      "Number of failed Ecoflow REST API requests",
+		}, []string{"operation"}),
+		apiLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "exporter",
+			Name:      "api_request_duration_seconds",
+			Help:      "Duration of Ecoflow REST API requests",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation"}),
+	}
+}
+
+// observe is used as ecoflow.PollerConfig.OnRequest handler
+func (h *healthMetrics) observe(operation, _ string, duration time.Duration, err error) {
+	h.apiLatency.WithLabelValues(operation).Observe(duration.Seconds())
+	if err != nil {
+		h.apiErrors.WithLabelValues(operation).Inc()
+	}
+}
@@ -0,0 +1,145 @@
+// Command ecoflow-exporter exposes the parameters of Ecoflow devices as Prometheus metrics on /metrics.
+//
+// The parameters are polled via Ecoflow REST API (ACCESS_KEY and SECRET_KEY environment variables are required)
+// and, optionally, received via MQTT (ECOFLOW_EMAIL and ECOFLOW_PASSWORD environment variables, -mqtt flag).
+//
+// Usage:
+//
+//	ACCESS_KEY=... SECRET_KEY=... ecoflow-exporter -listen :9090 -interval 30s -allow 'pd.*,bms_bmsStatus.*' -deny 'pd.wattsInSum'
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/tess1o/go-ecoflow"
+)
+
+func main() {
+	listen := flag.String("listen", ":9090", "address to expose metrics on")
+	interval := flag.Duration("interval", 30*time.Second, "REST API polling interval")
+	devices := flag.String("devices", "", "comma separated list of device serial numbers, all linked online devices are used if empty")
+	allow := flag.String("allow", "", "comma separated list of parameter patterns to export (e.g. 'pd.*,inv.outputWatts'), all if empty")
+	deny := flag.String("deny", "", "comma separated list of parameter patterns to skip")
+	baseUrl := flag.String("base-url", "", "custom Ecoflow API url")
+	useMqtt := flag.Bool("mqtt", false, "also subscribe to device parameters via MQTT (requires ECOFLOW_EMAIL and ECOFLOW_PASSWORD)")
+	flag.Parse()
+
+	accessKey := os.Getenv("ACCESS_KEY")
+	secretKey := os.Getenv("SECRET_KEY")
+	if accessKey == "" || secretKey == "" {
+		slog.Error("AccessKey and SecretKey are mandatory")
+		os.Exit(1)
+	}
+
+	filter, err := ecoflow.NewKeyFilter(splitList(*allow), splitList(*deny))
+	if err != nil {
+		slog.Error("Invalid parameters filter", "error", err)
+		os.Exit(1)
+	}
+
+	var options []func(*ecoflow.Client)
+	if *baseUrl != "" {
+		options = append(options, ecoflow.WithBaseUrl(*baseUrl))
+	}
+	client := ecoflow.NewEcoflowClient(accessKey, secretKey, options...)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	c := newCollector(filter)
+	health := newHealthMetrics()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(c, health.apiErrors, health.apiLatency)
+
+	poller, err := client.NewPoller(ecoflow.PollerConfig{
+		Interval:   *interval,
+		Devices:    splitList(*devices),
+		OnSnapshot: c.Update,
+		OnDevices:  c.UpdateDevices,
+		OnRequest:  health.observe,
+	})
+	if err != nil {
+		slog.Error("Unable to create poller", "error", err)
+		os.Exit(1)
+	}
+
+	if *useMqtt {
+		if err := subscribeMqtt(ctx, client, splitList(*devices), c); err != nil {
+			slog.Error("Unable to subscribe to MQTT", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	go func() {
+		_ = poller.Run(ctx)
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: *listen, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("Starting ecoflow exporter", "listen", *listen)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("Unable to start http server", "error", err)
+		os.Exit(1)
+	}
+}
+
+// subscribeMqtt subscribes to the parameters of the devices via MQTT. If no devices are configured, the linked devices are used
+func subscribeMqtt(ctx context.Context, client *ecoflow.Client, devices []string, c *collector) error {
+	mqttClient, err := ecoflow.NewMqttClient(ctx, ecoflow.MqttClientConfiguration{
+		Email:    os.Getenv("ECOFLOW_EMAIL"),
+		Password: os.Getenv("ECOFLOW_PASSWORD"),
+	})
+	if err != nil {
+		return err
+	}
+	if err = mqttClient.Connect(); err != nil {
+		return err
+	}
+
+	if len(devices) == 0 {
+		linked, err := client.Devices(ctx)
+		if err != nil {
+			return err
+		}
+		for _, d := range linked {
+			devices = append(devices, d.SN)
+		}
+	}
+
+	for _, sn := range devices {
+		if err = mqttClient.SubscribeForSnapshots(sn, c.Update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var result []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
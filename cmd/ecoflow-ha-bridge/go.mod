@@ -0,0 +1,17 @@
+module github.com/tess1o/go-ecoflow/cmd/ecoflow-ha-bridge
+
+go 1.22
+
+require (
+	github.com/eclipse/paho.mqtt.golang v1.4.3
+	github.com/tess1o/go-ecoflow v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/gorilla/websocket v1.5.0 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sync v0.7.0 // indirect
+)
+
+replace github.com/tess1o/go-ecoflow => ../..
@@ -0,0 +1,153 @@
+// Command ecoflow-ha-bridge republishes Ecoflow device parameters to a local MQTT broker using Home Assistant MQTT discovery.
+// Commands sent by Home Assistant (switches, numbers, selects) are executed via Ecoflow REST API.
+//
+// The parameters are polled via Ecoflow REST API (ACCESS_KEY and SECRET_KEY environment variables are required)
+// and, optionally, received via Ecoflow MQTT (ECOFLOW_EMAIL and ECOFLOW_PASSWORD environment variables, -ecoflow-mqtt flag).
+// The local broker credentials can be provided with MQTT_USERNAME and MQTT_PASSWORD environment variables.
+//
+// Usage:
+//
+//	ACCESS_KEY=... SECRET_KEY=... ecoflow-ha-bridge -broker tcp://localhost:1883 -interval 30s
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tess1o/go-ecoflow"
+	"github.com/tess1o/go-ecoflow/homeassistant"
+)
+
+func main() {
+	broker := flag.String("broker", "tcp://localhost:1883", "local MQTT broker url")
+	discoveryPrefix := flag.String("discovery-prefix", "homeassistant", "Home Assistant discovery prefix")
+	topicPrefix := flag.String("topic-prefix", "ecoflow", "prefix of state and command topics")
+	interval := flag.Duration("interval", 30*time.Second, "REST API polling interval")
+	devices := flag.String("devices", "", "comma separated list of device serial numbers, all linked online devices are used if empty")
+	useEcoflowMqtt := flag.Bool("ecoflow-mqtt", false, "also receive device parameters via Ecoflow MQTT (requires ECOFLOW_EMAIL and ECOFLOW_PASSWORD)")
+	flag.Parse()
+
+	accessKey := os.Getenv("ACCESS_KEY")
+	secretKey := os.Getenv("SECRET_KEY")
+	if accessKey == "" || secretKey == "" {
+		slog.Error("AccessKey and SecretKey are mandatory")
+		os.Exit(1)
+	}
+	client := ecoflow.NewEcoflowClient(accessKey, secretKey)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	opts := mqtt.NewClientOptions()
+	opts.AddBroker(*broker)
+	opts.SetClientID(fmt.Sprintf("ecoflow-ha-bridge-%d", time.Now().UnixNano()))
+	opts.SetUsername(os.Getenv("MQTT_USERNAME"))
+	opts.SetPassword(os.Getenv("MQTT_PASSWORD"))
+	opts.SetAutoReconnect(true)
+	// the subscriptions of the clean session are lost on reconnect, the bridge subscribes again
+	var bridge *homeassistant.Bridge
+	opts.SetOnConnectHandler(func(c mqtt.Client) {
+		bridge.OnConnect(c)
+	})
+	local := mqtt.NewClient(opts)
+	bridge = homeassistant.NewBridge(local, client, homeassistant.Config{
+		DiscoveryPrefix: *discoveryPrefix,
+		TopicPrefix:     *topicPrefix,
+	})
+	if token := local.Connect(); token.Wait() && token.Error() != nil {
+		slog.Error("Unable to connect to the local broker", "error", token.Error())
+		os.Exit(1)
+	}
+	defer local.Disconnect(250)
+
+	selected := splitList(*devices)
+	poller, err := client.NewPoller(ecoflow.PollerConfig{
+		Interval:   *interval,
+		Devices:    selected,
+		OnSnapshot: bridge.Update,
+		OnDevices: func(linked []ecoflow.Device) {
+			bridge.UpdateDevices(filterDevices(linked, selected))
+		},
+		OnRequest: func(operation, sn string, _ time.Duration, err error) {
+			if err != nil {
+				slog.Error("Ecoflow API request failed", "operation", operation, "sn", sn, "error", err)
+			}
+		},
+	})
+	if err != nil {
+		slog.Error("Unable to create poller", "error", err)
+		os.Exit(1)
+	}
+
+	if *useEcoflowMqtt {
+		if err = subscribeEcoflowMqtt(ctx, client, selected, bridge); err != nil {
+			slog.Error("Unable to subscribe to Ecoflow MQTT", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	slog.Info("Starting Home Assistant bridge", "broker", *broker)
+	_ = poller.Run(ctx)
+}
+
+func subscribeEcoflowMqtt(ctx context.Context, client *ecoflow.Client, devices []string, bridge *homeassistant.Bridge) error {
+	mqttClient, err := ecoflow.NewMqttClient(ctx, ecoflow.MqttClientConfiguration{
+		Email:    os.Getenv("ECOFLOW_EMAIL"),
+		Password: os.Getenv("ECOFLOW_PASSWORD"),
+	})
+	if err != nil {
+		return err
+	}
+	if err = mqttClient.Connect(); err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		linked, err := client.Devices(ctx)
+		if err != nil {
+			return err
+		}
+		for _, d := range linked {
+			devices = append(devices, d.SN)
+		}
+	}
+	for _, sn := range devices {
+		if err = mqttClient.SubscribeForSnapshots(sn, bridge.Update); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// filterDevices returns only the selected devices, or all devices if nothing is selected
+func filterDevices(devices []ecoflow.Device, selected []string) []ecoflow.Device {
+	if len(selected) == 0 {
+		return devices
+	}
+	var result []ecoflow.Device
+	for _, d := range devices {
+		for _, sn := range selected {
+			if d.SN == sn {
+				result = append(result, d)
+			}
+		}
+	}
+	return result
+}
+
+func splitList(s string) []string {
+	var result []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
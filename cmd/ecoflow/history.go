@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+	"github.com/tess1o/go-ecoflow/history"
+)
+
+const (
+	defaultHistoryDb      = "ecoflow-history.db"
+	historyTimeFormat     = time.RFC3339
+	downsampleInterval    = time.Hour
+	historyCommandsUsage  = "Usage: ecoflow history <record|show|export> [arguments]"
+	historyFormatCsv      = "csv"
+	historyFormatJson     = "json"
+	historyDefaultSince   = 24 * time.Hour
+	historyRecordInterval = time.Minute
+)
+
+func runHistory(args []string) error {
+	if len(args) == 0 {
+		return errors.New(historyCommandsUsage)
+	}
+	switch args[0] {
+	case "record":
+		return historyRecord(args[1:])
+	case "show":
+		return historyShow(args[1:])
+	case "export":
+		return historyExport(args[1:])
+	default:
+		return fmt.Errorf("unknown history command %q. %s", args[0], historyCommandsUsage)
+	}
+}
+
+// historyRecord polls the devices via REST API and records the parameters until interrupted
+func historyRecord(args []string) error {
+	fs := flag.NewFlagSet("history record", flag.ExitOnError)
+	db := fs.String("db", defaultHistoryDb, "history database file")
+	interval := fs.Duration("interval", historyRecordInterval, "REST API polling interval")
+	devices := fs.String("devices", "", "comma separated list of device serial numbers, all linked online devices are used if empty")
+	allow := fs.String("allow", "", "comma separated list of parameter patterns to record (e.g. 'pd.*,inv.outputWatts'), all if empty")
+	deny := fs.String("deny", "", "comma separated list of parameter patterns to skip")
+	rawRetention := fs.Duration("raw-retention", 0, "how long raw samples are kept before downsampling (default 7 days)")
+	rollupRetention := fs.Duration("rollup-retention", 0, "how long downsampled data is kept (default 365 days)")
+	baseUrl := fs.String("base-url", "", "custom Ecoflow API url")
+	_ = fs.Parse(args)
+
+	client, err := newClient(*baseUrl)
+	if err != nil {
+		return err
+	}
+	filter, err := ecoflow.NewKeyFilter(splitList(*allow), splitList(*deny))
+	if err != nil {
+		return err
+	}
+	store, err := history.Open(*db, history.Options{
+		RawRetention:    *rawRetention,
+		RollupRetention: *rollupRetention,
+		Filter:          filter,
+	})
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	poller, err := client.NewPoller(ecoflow.PollerConfig{
+		Interval:   *interval,
+		Devices:    splitList(*devices),
+		OnSnapshot: store.Write,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	go func() {
+		_ = store.Run(ctx, downsampleInterval)
+	}()
+	if err = poller.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+type historyQuery struct {
+	store *history.Store
+	sn    string
+	key   string
+	from  time.Time
+	to    time.Time
+	step  time.Duration
+}
+
+func parseHistoryQuery(fs *flag.FlagSet, args []string) (*historyQuery, error) {
+	db := fs.String("db", defaultHistoryDb, "history database file")
+	sn := fs.String("sn", "", "device serial number, the stored devices are listed if empty")
+	key := fs.String("key", "", "parameter name (e.g. pd.soc), the stored parameters are listed if empty")
+	since := fs.Duration("since", historyDefaultSince, "show the data for the given period up to now")
+	step := fs.Duration("step", 0, "aggregate the data by the given intervals (e.g. 1h), raw data is shown if 0")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *step < 0 {
+		return nil, errors.New("step must not be negative")
+	}
+	// history.Open creates the database, a mistyped path must not leave an empty file behind
+	if _, err := os.Stat(*db); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("history database %s doesn't exist, it's created by 'ecoflow history record'", *db)
+		}
+		return nil, err
+	}
+	store, err := history.Open(*db, history.Options{})
+	if err != nil {
+		return nil, err
+	}
+	to := time.Now()
+	return &historyQuery{store: store, sn: *sn, key: *key, from: to.Add(-*since), to: to, step: *step}, nil
+}
+
+// list prints the stored devices or parameters if sn or key is not set. It returns false if nothing was printed
+func (q *historyQuery) list(ctx context.Context, w io.Writer) (bool, error) {
+	var values []string
+	var err error
+	switch {
+	case q.sn == "":
+		values, err = q.store.Devices(ctx)
+	case q.key == "":
+		values, err = q.store.Keys(ctx, q.sn)
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, err
+	}
+	for _, v := range values {
+		fmt.Fprintln(w, v)
+	}
+	return true, nil
+}
+
+// historyShow prints the stored data of a device parameter as a table
+func historyShow(args []string) error {
+	fs := flag.NewFlagSet("history show", flag.ExitOnError)
+	last := fs.Bool("last", false, "show only the most recent value")
+	q, err := parseHistoryQuery(fs, args)
+	if err != nil {
+		return err
+	}
+	defer q.store.Close()
+
+	ctx := context.Background()
+	if listed, err := q.list(ctx, os.Stdout); listed {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	if *last {
+		p, err := q.store.Last(ctx, q.sn, q.key)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "TIME\tVALUE\n%s\t%s\n", p.Time.Format(historyTimeFormat), formatValue(p.Value))
+		return nil
+	}
+
+	if q.step == 0 {
+		points, err := q.store.Range(ctx, q.sn, q.key, q.from, q.to)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "TIME\tVALUE")
+		for _, p := range points {
+			fmt.Fprintf(w, "%s\t%s\n", p.Time.Format(historyTimeFormat), formatValue(p.Value))
+		}
+		return nil
+	}
+
+	points, err := q.store.Aggregate(ctx, q.sn, q.key, q.from, q.to, q.step)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "TIME\tCOUNT\tMIN\tMAX\tAVG\tLAST")
+	for _, p := range points {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\n", p.Time.Format(historyTimeFormat), p.Count,
+			formatValue(p.Min), formatValue(p.Max), formatValue(p.Avg), formatValue(p.Last))
+	}
+	return nil
+}
+
+// historyExport writes the stored data of a device parameter to stdout as csv or json
+func historyExport(args []string) error {
+	fs := flag.NewFlagSet("history export", flag.ExitOnError)
+	format := fs.String("format", historyFormatCsv, "output format: csv or json")
+	q, err := parseHistoryQuery(fs, args)
+	if err != nil {
+		return err
+	}
+	defer q.store.Close()
+
+	if *format != historyFormatCsv && *format != historyFormatJson {
+		return fmt.Errorf("unsupported format %q", *format)
+	}
+
+	ctx := context.Background()
+	if listed, err := q.list(ctx, os.Stdout); listed {
+		return err
+	}
+
+	var header []string
+	var records [][]string
+	var data interface{}
+	if q.step == 0 {
+		points, err := q.store.Range(ctx, q.sn, q.key, q.from, q.to)
+		if err != nil {
+			return err
+		}
+		header = []string{"time", "value"}
+		rows := make([]map[string]interface{}, 0, len(points))
+		for _, p := range points {
+			records = append(records, []string{p.Time.Format(historyTimeFormat), formatValue(p.Value)})
+			rows = append(rows, map[string]interface{}{"time": p.Time.Format(historyTimeFormat), "value": p.Value})
+		}
+		data = rows
+	} else {
+		points, err := q.store.Aggregate(ctx, q.sn, q.key, q.from, q.to, q.step)
+		if err != nil {
+			return err
+		}
+		header = []string{"time", "count", "min", "max", "avg", "last"}
+		rows := make([]map[string]interface{}, 0, len(points))
+		for _, p := range points {
+			records = append(records, []string{p.Time.Format(historyTimeFormat), strconv.FormatInt(p.Count, 10),
+				formatValue(p.Min), formatValue(p.Max), formatValue(p.Avg), formatValue(p.Last)})
+			rows = append(rows, map[string]interface{}{
+				"time": p.Time.Format(historyTimeFormat), "count": p.Count,
+				"min": p.Min, "max": p.Max, "avg": p.Avg, "last": p.Last,
+			})
+		}
+		data = rows
+	}
+
+	if *format == historyFormatJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	}
+	w := csv.NewWriter(os.Stdout)
+	if err = w.Write(header); err != nil {
+		return err
+	}
+	if err = w.WriteAll(records); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,83 @@
+// Command ecoflow is a command line tool for Ecoflow devices.
+//
+// Usage:
+//
+//	ecoflow <command> [arguments]
+//
+// Commands:
+//
+//	history record    poll the devices and save their parameters to the local history database
+//	history show      show the stored parameters of a device
+//	history export    export the stored parameters of a device as csv or json
+//	set               send a set command to a device, --dry-run prints the signed request without sending it
+//
+// Run "ecoflow <command> -h" to see the arguments of the command.
+// ACCESS_KEY and SECRET_KEY environment variables are required for the commands that use Ecoflow REST API.
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+const usage = `Usage: ecoflow <command> [arguments]
+
+Commands:
+  history record    poll the devices and save their parameters to the local history database
+  history show      show the stored parameters of a device
+  history export    export the stored parameters of a device as csv or json
+  set               send a set command to a device, --dry-run prints the signed request without sending it
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "history":
+		err = runHistory(os.Args[2:])
+	case "set":
+		err = runSet(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// newClient creates Ecoflow client using ACCESS_KEY and SECRET_KEY environment variables
+func newClient(baseUrl string) (*ecoflow.Client, error) {
+	accessKey := os.Getenv("ACCESS_KEY")
+	secretKey := os.Getenv("SECRET_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("ACCESS_KEY and SECRET_KEY environment variables are mandatory")
+	}
+	var options []func(*ecoflow.Client)
+	if baseUrl != "" {
+		options = append(options, ecoflow.WithBaseUrl(baseUrl))
+	}
+	return ecoflow.NewEcoflowClient(accessKey, secretKey, options...), nil
+}
+
+func splitList(s string) []string {
+	var result []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+// runSet sends a set command to the device. The command uses one of the addressing styles of ecoflow.Command:
+//
+//	ecoflow set -sn R331ZEB4ZEAL0528 -operate-type acChgCfg -module-type 5 -params '{"chgWatts":400,"chgPauseFlag":0}'
+//	ecoflow set -sn HW51ZOH4SF4E0000 -cmd-code WN511_SET_PERMANENT_WATTS_PACK -params '{"permanentWatts":200}'
+//	ecoflow set -sn MR51ZES5PG860274 -cmd-func 254 -params '{"cfgBeepEn":false}'
+//
+// With --dry-run the signed request is printed instead of being sent
+func runSet(args []string) error {
+	fs := flag.NewFlagSet("set", flag.ExitOnError)
+	sn := fs.String("sn", "", "device serial number")
+	operateType := fs.String("operate-type", "", "operateType of the command")
+	moduleType := fs.Int("module-type", 0, "moduleType of the command")
+	moduleSn := fs.String("module-sn", "", "module serial number (Power Kits)")
+	cmdCode := fs.String("cmd-code", "", "cmdCode of the command")
+	cmdFunc := fs.Int("cmd-func", 0, "cmdFunc of the cfg command")
+	cmdId := fs.Int("cmd-id", 0, "cmdId of the cfg command (default 17)")
+	params := fs.String("params", "{}", "command parameters as json object")
+	dryRun := fs.Bool("dry-run", false, "print the signed request instead of sending it")
+	baseUrl := fs.String("base-url", "", "custom Ecoflow API url")
+	_ = fs.Parse(args)
+
+	if *sn == "" {
+		return errors.New("sn is mandatory")
+	}
+	cmd := ecoflow.Command{
+		Sn:          *sn,
+		OperateType: *operateType,
+		ModuleType:  ecoflow.ModuleType(*moduleType),
+		ModuleSn:    *moduleSn,
+		CmdCode:     *cmdCode,
+		CmdFunc:     *cmdFunc,
+		CmdId:       *cmdId,
+	}
+	if err := json.Unmarshal([]byte(*params), &cmd.Params); err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+
+	client, err := newClient(*baseUrl)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if *dryRun {
+		ctx = ecoflow.ContextWithDryRun(ctx)
+	}
+	resp, err := client.SendCommand(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	if resp.DryRun != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(resp.DryRun)
+	}
+	fmt.Printf("code=%s message=%s\n", resp.Code, resp.Message)
+	return nil
+}
@@ -0,0 +1,266 @@
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Command is a set command of a device. The device wrappers build the commands, a command can be sent with the
+// REST API (Client.SendCommand) or published to the MQTT broker (MqttClient.PublishCommand).
+// A command uses one of the addressing styles, the style is inferred from the fields unless Style is set:
+//   - OperateType and ModuleType (PowerStation, Glacier, Wave, etc.):
+//     { "id":"...", "sn":"R331ZEB4ZEAL0528", "moduleType":5, "operateType":"acOutCfg", "params":{ ... } }
+//   - CmdCode (PowerStream, Smart Plug, Delta Pro Ultra): { "id":"...", "sn":"HW51ZOH4SF4E0000", "cmdCode":"WN511_SET_PERMANENT_WATTS_PACK", "params":{ ... } }
+//   - cmdSet and id in Params, OperateType is "TCP" or empty (Smart Home Panel, Delta Pro):
+//     { "id":"...", "sn":"SP10ZAW5ZE9E0052", "operateType":"TCP", "params":{ "cmdSet":11, "id":16, ... } }
+//   - ModuleSn with OperateType and ModuleType (Power Kit modules):
+//     { "id":"...", "sn":"M106ZAB4Z000001F", "moduleSn":"M106ZAB4Z000001F", "moduleType":15362, "operateType":"dsgCfg", "params":{ ... } }
+//   - CmdFunc and CmdId of the cfg header (Delta Pro 3, River 3, Smart Home Panel 2), the cfg commands have no id:
+//     { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ ... } }
+type Command struct {
+	// Style is inferred from the fields if it's CommandStyleAuto
+	Style       CommandStyle
+	Sn          string
+	OperateType string
+	ModuleType  ModuleType
+	CmdCode     string
+	ModuleSn    string
+	CmdId       int
+	CmdFunc     int
+	Params      map[string]interface{}
+	// Id is generated by Request if it's empty, see NextCommandId
+	Id string
+}
+
+// CommandStyle is the addressing style of a command, see Command
+type CommandStyle int
+
+const (
+	CommandStyleAuto        CommandStyle = iota
+	CommandStyleOperateType              // operateType and moduleType
+	CommandStyleCmdCode                  // cmdCode
+	CommandStyleTcp                      // cmdSet and id in the params
+	CommandStyleModule                   // moduleSn, operateType and moduleType, the moduleSn is always sent (Power Kits)
+	CommandStyleCfg                      // the cfg header with cmdFunc
+)
+
+// style returns the explicit style of the command or infers it from the fields
+func (c *Command) style() CommandStyle {
+	switch {
+	case c.Style != CommandStyleAuto:
+		return c.Style
+	case c.CmdFunc != 0:
+		return CommandStyleCfg
+	case c.CmdCode != "":
+		return CommandStyleCmdCode
+	case c.ModuleSn != "":
+		return CommandStyleModule
+	}
+	if _, ok := c.Params["cmdSet"]; ok && (c.OperateType == "" || c.OperateType == "TCP") {
+		return CommandStyleTcp
+	}
+	return CommandStyleOperateType
+}
+
+// lastCommandId is the last id returned by NextCommandId
+var lastCommandId atomic.Int64
+
+// NextCommandId returns a unique command id. The id is the current time in milliseconds, but it's incremented
+// if the commands are created in the same millisecond, so the ids don't collide in tight loops
+func NextCommandId() string {
+	for {
+		last := lastCommandId.Load()
+		id := time.Now().UnixMilli()
+		if id <= last {
+			id = last + 1
+		}
+		if lastCommandId.CompareAndSwap(last, id) {
+			return strconv.FormatInt(id, 10)
+		}
+	}
+}
+
+// Validate checks that the command has the fields of its addressing style and no fields of the other styles
+func (c *Command) Validate() error {
+	if c.Sn == "" {
+		return errors.New("command sn is empty")
+	}
+	style := c.style()
+	switch style {
+	case CommandStyleCfg:
+		if c.CmdFunc == 0 {
+			return errors.New("cfg command must have cmdFunc")
+		}
+		if c.OperateType != "" || c.CmdCode != "" || c.ModuleSn != "" || c.ModuleType != 0 {
+			return errors.New("cfg command can't have operateType, moduleType, cmdCode or moduleSn")
+		}
+	case CommandStyleCmdCode:
+		if c.CmdCode == "" {
+			return errors.New("cmdCode command must have cmdCode")
+		}
+		if c.OperateType != "" || c.ModuleSn != "" || c.ModuleType != 0 {
+			return errors.New("cmdCode command can't have operateType, moduleType or moduleSn")
+		}
+	case CommandStyleModule:
+		if c.OperateType == "" {
+			return errors.New("moduleSn command must have operateType")
+		}
+		if c.CmdCode != "" {
+			return errors.New("moduleSn command can't have cmdCode")
+		}
+	case CommandStyleTcp:
+		if _, ok := c.Params["cmdSet"]; !ok {
+			return errors.New("TCP command must have cmdSet parameter")
+		}
+		if c.CmdCode != "" || c.ModuleSn != "" {
+			return errors.New("TCP command can't have cmdCode or moduleSn")
+		}
+	case CommandStyleOperateType:
+		if c.OperateType == "" {
+			return errors.New("command has no operateType, cmdCode, cmdSet or cmdFunc")
+		}
+		if c.CmdCode != "" || c.ModuleSn != "" {
+			return errors.New("operateType command can't have cmdCode or moduleSn")
+		}
+	default:
+		return fmt.Errorf("unknown command style %d", style)
+	}
+	if c.CmdFunc != 0 && style != CommandStyleCfg {
+		return errors.New("cmdFunc can be used only with the cfg commands")
+	}
+	if c.CmdId != 0 && style != CommandStyleCfg {
+		return errors.New("cmdId can be used only with cmdFunc")
+	}
+	return nil
+}
+
+// Request returns the JSON request of the command, it can be sent by Client.SetDeviceParameter
+func (c *Command) Request() (map[string]interface{}, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	params := c.Params
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	style := c.style()
+	if c.Id == "" && style != CommandStyleCfg {
+		c.Id = NextCommandId()
+	}
+
+	var cmdReq interface{}
+	switch style {
+	case CommandStyleCfg:
+		cmdId := c.CmdId
+		if cmdId == 0 {
+			cmdId = cfgSetCmdId
+		}
+		cmdReq = cfgSetRequest{
+			Sn:      c.Sn,
+			CmdId:   cmdId,
+			DirDest: cfgSetDir,
+			DirSrc:  cfgSetDir,
+			CmdFunc: c.CmdFunc,
+			Dest:    cfgSetDest,
+			NeedAck: true,
+			Params:  params,
+		}
+	case CommandStyleModule:
+		cmdReq = powerKitSetCmdRequest{
+			Id:          c.Id,
+			Sn:          c.Sn,
+			ModuleSn:    c.ModuleSn,
+			ModuleType:  PowerKitModuleType(c.ModuleType),
+			OperateType: c.OperateType,
+			Params:      params,
+		}
+	default:
+		cmdReq = CmdSetRequest{
+			Id:          c.Id,
+			OperateType: c.OperateType,
+			ModuleType:  c.ModuleType,
+			CmdCode:     c.CmdCode,
+			Sn:          c.Sn,
+			Params:      params,
+		}
+	}
+
+	jsonData, err := json.Marshal(cmdReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var req map[string]interface{}
+
+	err = json.Unmarshal(jsonData, &req)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// SendCommand sends the command with the REST API
+func (c *Client) SendCommand(ctx context.Context, cmd Command) (*CmdSetResponse, error) {
+	req, err := cmd.Request()
+	if err != nil {
+		return nil, err
+	}
+	return c.SetDeviceParameter(ctx, req)
+}
+
+// mqttSetTopic is the topic of the set commands of the device, the replies are published to the "set_reply" topic
+const mqttSetTopic = "/app/%s/%s/thing/property/set"
+
+// PublishCommand publishes the command to the device's set topic and returns the published payload. The app protocol
+// requires the "from" and "version" fields, they're added to the request.
+// The set topic accepts the JSON commands only: operateType/moduleType and cmdSet (TCP) styles. The cmdCode, moduleSn
+// and cmdFunc commands are encoded differently by the app, they must be sent with Client.SendCommand.
+// The client must be connected to the broker, the call returns when the broker acknowledges the message or ctx is done.
+// In the dry-run mode (MqttClientConfiguration.DryRun or ContextWithDryRun) the payload is returned without publishing
+func (m *MqttClient) PublishCommand(ctx context.Context, cmd Command) ([]byte, error) {
+	payload, err := cmd.mqttPayload()
+	if err != nil {
+		return nil, err
+	}
+	topic := fmt.Sprintf(mqttSetTopic, m.connectionConfig.UserId, cmd.Sn)
+	if m.dryRun || IsDryRun(ctx) {
+		recordDryRun(ctx, &DryRunRequest{Method: dryRunPublish, Url: topic, Body: payload})
+		return payload, nil
+	}
+	token := m.Client.Publish(topic, 1, false, payload)
+	select {
+	case <-token.Done():
+		if err = token.Error(); err != nil {
+			return nil, err
+		}
+		return payload, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// mqttPayload returns the JSON payload of the command for the MQTT set topic
+func (c *Command) mqttPayload() ([]byte, error) {
+	switch c.style() {
+	case CommandStyleCmdCode:
+		return nil, errors.New("cmdCode commands can't be published to the MQTT set topic")
+	case CommandStyleCfg:
+		return nil, errors.New("cmdFunc commands can't be published to the MQTT set topic")
+	case CommandStyleModule:
+		return nil, errors.New("moduleSn commands can't be published to the MQTT set topic")
+	}
+	req, err := c.Request()
+	if err != nil {
+		return nil, err
+	}
+	req["from"] = "Android"
+	if _, ok := req["version"]; !ok {
+		req["version"] = "1.0"
+	}
+	return json.Marshal(req)
+}
@@ -0,0 +1,199 @@
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+func TestNextCommandId(t *testing.T) {
+	ids := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		id := NextCommandId()
+		if ids[id] {
+			t.Fatalf("duplicate id %s after %d commands", id, i)
+		}
+		ids[id] = true
+	}
+}
+
+func TestCommandRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmd      Command
+		expected map[string]interface{}
+	}{
+		{
+			name: "operateType",
+			cmd:  Command{Sn: "R331ZEB4ZEAL0528", OperateType: "acOutCfg", ModuleType: ModuleTypeInv, Params: map[string]interface{}{"enabled": 1}},
+			expected: map[string]interface{}{"id": "1", "sn": "R331ZEB4ZEAL0528", "operateType": "acOutCfg", "moduleType": float64(ModuleTypeInv),
+				"params": map[string]interface{}{"enabled": float64(1)}},
+		},
+		{
+			name: "cmdCode",
+			cmd:  Command{Sn: "HW51ZOH4SF4E0000", CmdCode: "WN511_SET_PERMANENT_WATTS_PACK", Params: map[string]interface{}{"permanentWatts": 200}},
+			expected: map[string]interface{}{"id": "1", "sn": "HW51ZOH4SF4E0000", "cmdCode": "WN511_SET_PERMANENT_WATTS_PACK",
+				"params": map[string]interface{}{"permanentWatts": float64(200)}},
+		},
+		{
+			name: "cmdSet",
+			cmd:  Command{Sn: "SP10ZAW5ZE9E0052", OperateType: "TCP", Params: map[string]interface{}{"cmdSet": 11, "id": 16}},
+			expected: map[string]interface{}{"id": "1", "sn": "SP10ZAW5ZE9E0052", "operateType": "TCP",
+				"params": map[string]interface{}{"cmdSet": float64(11), "id": float64(16)}},
+		},
+		{
+			name: "moduleSn",
+			cmd:  Command{Sn: "M106ZAB4Z000001F", ModuleSn: "M106ZAB4Z000001F", OperateType: "dsgCfg", ModuleType: 15362},
+			expected: map[string]interface{}{"id": "1", "sn": "M106ZAB4Z000001F", "moduleSn": "M106ZAB4Z000001F", "operateType": "dsgCfg",
+				"moduleType": float64(15362), "params": map[string]interface{}{}},
+		},
+		{
+			name: "module without moduleSn",
+			cmd:  Command{Style: CommandStyleModule, Sn: "M106ZAB4Z000001F", OperateType: "socUpperLimit", Params: map[string]interface{}{"maxChgSoc": 90}},
+			expected: map[string]interface{}{"id": "1", "sn": "M106ZAB4Z000001F", "moduleSn": "", "operateType": "socUpperLimit",
+				"moduleType": float64(0), "params": map[string]interface{}{"maxChgSoc": float64(90)}},
+		},
+		{
+			name: "cmdFunc",
+			cmd:  Command{Sn: "HD31ZAS4HGB10001", CmdFunc: 12, Params: map[string]interface{}{"backupReserveSoc": 30}},
+			expected: map[string]interface{}{"sn": "HD31ZAS4HGB10001", "cmdId": float64(17), "dirDest": float64(1), "dirSrc": float64(1),
+				"cmdFunc": float64(12), "dest": float64(2), "needAck": true, "params": map[string]interface{}{"backupReserveSoc": float64(30)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.cmd.CmdFunc == 0 {
+				tt.cmd.Id = "1"
+			}
+			req, err := tt.cmd.Request()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(req, tt.expected) {
+				t.Errorf("got %v, expected %v", req, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCommandValidate(t *testing.T) {
+	invalid := []Command{
+		{OperateType: "acOutCfg", ModuleType: ModuleTypeInv},
+		{Sn: "R331ZEB4ZEAL0528"},
+		{Sn: "R331ZEB4ZEAL0528", CmdCode: "WN511_SET_PERMANENT_WATTS_PACK", OperateType: "acOutCfg"},
+		{Sn: "R331ZEB4ZEAL0528", CmdFunc: 254, ModuleType: ModuleTypePd},
+		{Sn: "R331ZEB4ZEAL0528", ModuleSn: "M106ZAB4Z000001F"},
+		{Sn: "R331ZEB4ZEAL0528", OperateType: "acOutCfg", CmdId: 17},
+		{Style: CommandStyleCmdCode, Sn: "R331ZEB4ZEAL0528", OperateType: "acOutCfg"},
+		{Style: CommandStyleModule, Sn: "R331ZEB4ZEAL0528"},
+	}
+	for _, cmd := range invalid {
+		if _, err := cmd.Request(); err == nil {
+			t.Errorf("expected error for %+v", cmd)
+		}
+	}
+}
+
+func TestSendCommand(t *testing.T) {
+	client, last := newTestClient(t, nil)
+	ctx := context.Background()
+
+	cmd := Command{Sn: "R331ZEB4ZEAL0528", OperateType: "acOutCfg", ModuleType: ModuleTypeInv, Params: map[string]interface{}{"enabled": 1}}
+	if _, err := client.SendCommand(ctx, cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := (*last)["id"]
+	if _, err := client.SendCommand(ctx, cmd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first == nil || first == (*last)["id"] {
+		t.Errorf("expected unique ids, got %v and %v", first, (*last)["id"])
+	}
+}
+
+type fakeToken struct {
+	done chan struct{}
+	err  error
+}
+
+func (t *fakeToken) Wait() bool {
+	<-t.done
+	return true
+}
+
+func (t *fakeToken) WaitTimeout(d time.Duration) bool {
+	select {
+	case <-t.done:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func (t *fakeToken) Done() <-chan struct{} { return t.done }
+func (t *fakeToken) Error() error          { return t.err }
+
+// fakeMqttClient records the published messages, the tokens are completed if acked is true
+type fakeMqttClient struct {
+	mqtt.Client
+	acked     bool
+	topic     string
+	published []byte
+}
+
+func (f *fakeMqttClient) Publish(topic string, _ byte, _ bool, payload interface{}) mqtt.Token {
+	f.topic = topic
+	f.published = payload.([]byte)
+	token := &fakeToken{done: make(chan struct{})}
+	if f.acked {
+		close(token.done)
+	}
+	return token
+}
+
+func TestPublishCommand(t *testing.T) {
+	broker := &fakeMqttClient{acked: true}
+	client := &MqttClient{Client: broker, connectionConfig: &MqttConnectionConfig{UserId: "1234"}}
+	ctx := context.Background()
+
+	cmd := Command{Sn: "R331ZEB4ZEAL0528", OperateType: "acOutCfg", ModuleType: ModuleTypeInv, Params: map[string]interface{}{"enabled": 1}}
+	payload, err := client.PublishCommand(ctx, cmd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if broker.topic != "/app/1234/R331ZEB4ZEAL0528/thing/property/set" || string(broker.published) != string(payload) {
+		t.Errorf("unexpected message %s %s", broker.topic, broker.published)
+	}
+	var req map[string]interface{}
+	if err = json.Unmarshal(payload, &req); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if req["from"] != "Android" || req["version"] != "1.0" || req["operateType"] != "acOutCfg" || req["id"] == nil {
+		t.Errorf("unexpected payload %v", req)
+	}
+
+	unsupported := []Command{
+		{Sn: "HW51ZOH4SF4E0000", CmdCode: "WN511_SET_PERMANENT_WATTS_PACK", Params: map[string]interface{}{"permanentWatts": 200}},
+		{Sn: "MR51ZES5PG860274", CmdFunc: 254, Params: map[string]interface{}{"cfgBeepEn": false}},
+		{Sn: "M106ZAB4Z000001F", ModuleSn: "M106ZAB4Z000001F", OperateType: "dsgCfg", ModuleType: 15362},
+	}
+	for _, c := range unsupported {
+		broker.published = nil
+		if _, err = client.PublishCommand(ctx, c); err == nil || broker.published != nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+
+	// the publish doesn't block forever if the broker is not connected
+	broker.acked = false
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err = client.PublishCommand(ctx, cmd); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline error, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+)
+
+// Ecoflow documentation: https://developer-eu.ecoflow.com/us/document/deltapro3
+// Delta Pro 3 doesn't support the "cmdSet 32" commands of PowerStationPro, it's configured by "cfg*" parameters.
+// The parameters are reported without module prefixes, e.g. "bmsBattSoc", "powInSumW"
+
+type DeltaPro3 struct {
+	cfgPowerStation
+}
+
+func newDeltaPro3(c *Client, sn string) *DeltaPro3 {
+	return &DeltaPro3{cfgPowerStation{c: c, sn: sn, acChargingMin: 400, acChargingMax: 2900}}
+}
+
+// DeltaPro3Status is the state of a Delta Pro 3
+type DeltaPro3Status struct {
+	// Soc is the battery level, %
+	Soc float64
+	// InputWatts and OutputWatts are the total input and output power, W
+	InputWatts  float64
+	OutputWatts float64
+	// ChargeRemainingMinutes and DischargeRemainingMinutes are the estimated times to full and to empty
+	ChargeRemainingMinutes    int
+	DischargeRemainingMinutes int
+	AcInputWatts              float64
+	// AcHvOutputWatts is the power of the high voltage (240 V) AC outlets, AcLvOutputWatts of the low voltage (120 V) ones
+	AcHvOutputWatts float64
+	AcLvOutputWatts float64
+	DcOutputWatts   float64
+	// SolarHvWatts and SolarLvWatts are the power of the high and low voltage solar inputs
+	SolarHvWatts    float64
+	SolarLvWatts    float64
+	XboostEnabled   bool
+	MaxChargeSoc    int
+	MinDischargeSoc int
+	// AcChargingWatts is the max AC charging power, W
+	AcChargingWatts      int
+	BackupReserveEnabled bool
+	BackupReserveSoc     int
+	BeepEnabled          bool
+}
+
+// GetStatus returns the typed state of the power station
+func (s *DeltaPro3) GetStatus(ctx context.Context) (*DeltaPro3Status, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeDeltaPro3Status(params)
+}
+
+// DecodeDeltaPro3Status decodes the Delta Pro 3 state from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// Missing parameters have zero values, an error is returned only if the battery level is not found
+func DecodeDeltaPro3Status(params map[string]interface{}) (*DeltaPro3Status, error) {
+	soc, ok := NumericValue(params["bmsBattSoc"])
+	if !ok {
+		return nil, errors.New("bmsBattSoc parameter is not found")
+	}
+	value := func(key string) float64 {
+		v, _ := NumericValue(params[key])
+		return v
+	}
+	return &DeltaPro3Status{
+		Soc:                       soc,
+		InputWatts:                value("powInSumW"),
+		OutputWatts:               value("powOutSumW"),
+		ChargeRemainingMinutes:    int(value("bmsChgRemTime")),
+		DischargeRemainingMinutes: int(value("bmsDsgRemTime")),
+		AcInputWatts:              value("powGetAcIn"),
+		AcHvOutputWatts:           value("powGetAcHvOut"),
+		AcLvOutputWatts:           value("powGetAcLvOut"),
+		DcOutputWatts:             value("powGet12v"),
+		SolarHvWatts:              value("powGetPvH"),
+		SolarLvWatts:              value("powGetPvL"),
+		XboostEnabled:             value("xboostEn") == 1,
+		MaxChargeSoc:              int(value("cmsMaxChgSoc")),
+		MinDischargeSoc:           int(value("cmsMinDsgSoc")),
+		AcChargingWatts:           int(value("plugInInfoAcInChgPowMax")),
+		BackupReserveEnabled:      value("energyBackupEn") == 1,
+		BackupReserveSoc:          int(value("energyBackupStartSoc")),
+		BeepEnabled:               value("enBeep") == 1,
+	}, nil
+}
+
+// SetAcHvOutput Setting the high voltage AC output switch
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgHvAcOutOpen":true } }
+func (s *DeltaPro3) SetAcHvOutput(ctx context.Context, enabled bool) (*CmdSetResponse, error) {
+	params := make(map[string]interface{})
+	params["cfgHvAcOutOpen"] = enabled
+	return s.setParameter(ctx, params)
+}
+
+// SetAcLvOutput Setting the low voltage AC output switch
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgLvAcOutOpen":true } }
+func (s *DeltaPro3) SetAcLvOutput(ctx context.Context, enabled bool) (*CmdSetResponse, error) {
+	params := make(map[string]interface{})
+	params["cfgLvAcOutOpen"] = enabled
+	return s.setParameter(ctx, params)
+}
+
+// SetBeep Setting the beep switch
+// { "sn":"MR51ZES5PG860274", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgBeepEn":false } }
+func (s *DeltaPro3) SetBeep(ctx context.Context, enabled bool) (*CmdSetResponse, error) {
+	params := make(map[string]interface{})
+	params["cfgBeepEn"] = enabled
+	return s.setParameter(ctx, params)
+}
@@ -0,0 +1,69 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeltaPro3Status(t *testing.T) {
+	client, _ := newTestClient(t, map[string]interface{}{
+		"bmsBattSoc":           64,
+		"powInSumW":            812,
+		"powGetPvH":            812,
+		"xboostEn":             1,
+		"energyBackupEn":       1,
+		"energyBackupStartSoc": 30,
+	})
+	status, err := client.GetDeltaPro3("MR51ZES5PG860274").GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := DeltaPro3Status{Soc: 64, InputWatts: 812, SolarHvWatts: 812, XboostEnabled: true, BackupReserveEnabled: true, BackupReserveSoc: 30}
+	if *status != expected {
+		t.Errorf("got %+v, expected %+v", *status, expected)
+	}
+}
+
+func TestDeltaPro3Setters(t *testing.T) {
+	client, last := newTestClient(t, nil)
+	ps := client.GetDeltaPro3("MR51ZES5PG860274")
+
+	runSetterTests(t, last, []setterTest{
+		{
+			name: "Backup reserve",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetBackupReserve(ctx, true, 40) },
+			params: map[string]interface{}{"cfgEnergyBackup": map[string]interface{}{"energyBackupEn": true,
+				"energyBackupStartSoc": float64(40)}},
+		},
+		{
+			name:   "High voltage AC output",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetAcHvOutput(ctx, false) },
+			params: map[string]interface{}{"cfgHvAcOutOpen": false},
+		},
+		{
+			name:   "AC charging power",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetAcChargingWatts(ctx, 2900) },
+			params: map[string]interface{}{"cfgPlugInInfoAcInChgPowMax": float64(2900)},
+		},
+		{
+			name: "AC charging power below the Delta Pro 3 range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetAcChargingWatts(ctx, 300) },
+		},
+		{
+			name: "Charge limit out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetMaxChargeSoc(ctx, 40) },
+		},
+		{
+			name: "Backup reserve out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetBackupReserve(ctx, true, 4) },
+		},
+	})
+
+	// the settings are sent with the cfg header
+	if _, err := ps.SetBeep(context.Background(), false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if (*last)["cmdId"] != float64(17) || (*last)["cmdFunc"] != float64(254) || (*last)["needAck"] != true {
+		t.Errorf("unexpected request %v", *last)
+	}
+}
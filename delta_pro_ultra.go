@@ -0,0 +1,250 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// Ecoflow documentation: https://developer-eu.ecoflow.com/us/document/deltaproultra
+// Delta Pro Ultra is an inverter with up to 5 battery packs. The commands are sent with "YJ751_*" command codes,
+// the parameters are grouped by the source: the totals and the inverter ("hs_yj751_pd_appshow_addr.*"),
+// the settings ("hs_yj751_pd_backend_addr.*") and the battery packs ("hs_yj751_bms_slave_addr.<index>.*")
+
+const (
+	ultraAppShowKey = "hs_yj751_pd_appshow_addr"
+	ultraBackendKey = "hs_yj751_pd_backend_addr"
+	ultraBatteryKey = "hs_yj751_bms_slave_addr"
+)
+
+type DeltaProUltra struct {
+	c  *Client
+	sn string
+}
+
+func (s *DeltaProUltra) GetSn() string {
+	return s.sn
+}
+
+// DeltaProUltraInverter is the state of the inverter (the main unit)
+type DeltaProUltraInverter struct {
+	// AcInputWatts is the power of the AC inputs (C20 and the 30 A input), W
+	AcInputWatts float64
+	// SolarHvWatts and SolarLvWatts are the power of the high and low voltage solar inputs, W
+	SolarHvWatts float64
+	SolarLvWatts float64
+	// AcOutputWatts is the power of all AC outputs, W
+	AcOutputWatts float64
+	// DcOutputWatts is the power of the USB and 12 V outputs, W
+	DcOutputWatts float64
+	AcEnabled     bool
+	XboostEnabled bool
+	DcEnabled     bool
+}
+
+// DeltaProUltraBattery is the state of a battery pack
+type DeltaProUltraBattery struct {
+	// Index is the position of the pack in the stack, starting from 0
+	Index int
+	Sn    string
+	// Soc is the battery level, %
+	Soc float64
+	// Voltage is the pack voltage, V
+	Voltage float64
+	// Temperature is the pack temperature, ℃
+	Temperature float64
+	// Watts is the pack power, positive while charging
+	Watts float64
+}
+
+// DeltaProUltraStatus is the state of a Delta Pro Ultra with all connected battery packs
+type DeltaProUltraStatus struct {
+	// Soc is the level of all batteries, %
+	Soc float64
+	// InputWatts and OutputWatts are the total input and output power, W
+	InputWatts  float64
+	OutputWatts float64
+	// RemainingMinutes is the remaining charging or discharging time
+	RemainingMinutes int
+	MaxChargeSoc     int
+	MinDischargeSoc  int
+	// BackupReserveEnabled and BackupReserveSoc are the energy management settings
+	BackupReserveEnabled bool
+	BackupReserveSoc     int
+	Inverter             DeltaProUltraInverter
+	// Batteries are the connected battery packs sorted by index
+	Batteries []DeltaProUltraBattery
+}
+
+// GetStatus returns the typed state of the power station
+func (s *DeltaProUltra) GetStatus(ctx context.Context) (*DeltaProUltraStatus, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeDeltaProUltraStatus(params)
+}
+
+// DecodeDeltaProUltraStatus decodes the Delta Pro Ultra state from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// Missing parameters have zero values, an error is returned only if the battery level is not found
+func DecodeDeltaProUltraStatus(params map[string]interface{}) (*DeltaProUltraStatus, error) {
+	soc, ok := NumericValue(params[ultraAppShowKey+".soc"])
+	if !ok {
+		return nil, errors.New(ultraAppShowKey + ".soc parameter is not found")
+	}
+	show := func(key string) float64 {
+		v, _ := NumericValue(params[ultraAppShowKey+"."+key])
+		return v
+	}
+	backend := func(key string) float64 {
+		v, _ := NumericValue(params[ultraBackendKey+"."+key])
+		return v
+	}
+
+	status := &DeltaProUltraStatus{
+		Soc:                  soc,
+		InputWatts:           show("wattsInSum"),
+		OutputWatts:          show("wattsOutSum"),
+		RemainingMinutes:     int(show("remainTime")),
+		MaxChargeSoc:         int(backend("chgMaxSoc")),
+		MinDischargeSoc:      int(backend("dsgMinSoc")),
+		BackupReserveEnabled: backend("energyBackupEn") == 1,
+		BackupReserveSoc:     int(backend("energyBackupStartSoc")),
+		Inverter: DeltaProUltraInverter{
+			AcInputWatts:  show("inAc5p8Pwr") + show("inAcC20Pwr"),
+			SolarHvWatts:  show("inHvMpptPwr"),
+			SolarLvWatts:  show("inLvMpptPwr"),
+			AcOutputWatts: show("outAcL11Pwr") + show("outAcL12Pwr") + show("outAcL21Pwr") + show("outAcL22Pwr") + show("outAcTtPwr") + show("outAc5p8Pwr"),
+			DcOutputWatts: show("outUsb1Pwr") + show("outUsb2Pwr") + show("outTypec1Pwr") + show("outTypec2Pwr") + show("outAdsPwr"),
+			AcEnabled:     backend("acOutState") == 1,
+			XboostEnabled: backend("acXboost") == 1,
+			DcEnabled:     backend("dcOutState") == 1,
+		},
+	}
+	for index, m := range indexedObjects(params, ultraBatteryKey) {
+		battery := DeltaProUltraBattery{Index: index}
+		battery.Sn, _ = m["sn"].(string)
+		battery.Soc, _ = NumericValue(m["soc"])
+		voltage, _ := NumericValue(m["vol"])
+		battery.Voltage = voltage / 1000
+		battery.Temperature, _ = NumericValue(m["temp"])
+		battery.Watts, _ = NumericValue(m["inputWatts"])
+		if out, ok := NumericValue(m["outputWatts"]); ok {
+			battery.Watts -= out
+		}
+		status.Batteries = append(status.Batteries, battery)
+	}
+	sort.Slice(status.Batteries, func(i, j int) bool { return status.Batteries[i].Index < status.Batteries[j].Index })
+	return status, nil
+}
+
+// indexedObjects groups the parameters of the indexed sub-devices (e.g. battery packs), see keyedObjects
+func indexedObjects(params map[string]interface{}, prefix string) map[int]map[string]interface{} {
+	objects := make(map[int]map[string]interface{})
+	for key, m := range keyedObjects(params, prefix) {
+		if i, err := strconv.Atoi(key); err == nil {
+			objects[i] = m
+		}
+	}
+	return objects
+}
+
+// SetAcOutput AC output settings(enabled: AC switch, 0: off, 1: on; xboost: X-Boost switch, 0: off, 1: on)
+// { "id":123456789, "version":"1.0", "sn":"Y711ZAB59G170001", "cmdCode":"YJ751_PD_AC_DSG_SET", "params":{ "enable":1, "xboost":0 } }
+func (s *DeltaProUltra) SetAcOutput(ctx context.Context, enabled, xboost SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled || xboost != SettingEnabled && xboost != SettingDisabled {
+		return nil, errors.New("enable and xboost out of range. Range 0:1")
+	}
+	params := make(map[string]interface{})
+	params["enable"] = enabled
+	params["xboost"] = xboost
+	return s.setParameter(ctx, "YJ751_PD_AC_DSG_SET", params)
+}
+
+// SetDcOutput DC output switch (0: off, 1: on)
+// { "id":123456789, "version":"1.0", "sn":"Y711ZAB59G170001", "cmdCode":"YJ751_PD_DC_SWITCH_SET", "params":{ "enable":1 } }
+func (s *DeltaProUltra) SetDcOutput(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("enable out of range. Range 0:1")
+	}
+	params := make(map[string]interface{})
+	params["enable"] = enabled
+	return s.setParameter(ctx, "YJ751_PD_DC_SWITCH_SET", params)
+}
+
+// SetMaxChargeSoc Charge limit (range 50:100)
+// { "id":123456789, "version":"1.0", "sn":"Y711ZAB59G170001", "cmdCode":"YJ751_PD_CHG_SOC_MAX_SET", "params":{ "maxChgSoc":100 } }
+func (s *DeltaProUltra) SetMaxChargeSoc(ctx context.Context, maxChgSoc int) (*CmdSetResponse, error) {
+	if maxChgSoc < 50 || maxChgSoc > 100 {
+		return nil, errors.New("maxChgSoc out of range. Range 50:100")
+	}
+	params := make(map[string]interface{})
+	params["maxChgSoc"] = maxChgSoc
+	return s.setParameter(ctx, "YJ751_PD_CHG_SOC_MAX_SET", params)
+}
+
+// SetMinDischargeSoc Discharge limit (range 0:30)
+// { "id":123456789, "version":"1.0", "sn":"Y711ZAB59G170001", "cmdCode":"YJ751_PD_DSG_SOC_MIN_SET", "params":{ "minDsgSoc":10 } }
+func (s *DeltaProUltra) SetMinDischargeSoc(ctx context.Context, minDsgSoc int) (*CmdSetResponse, error) {
+	if minDsgSoc < 0 || minDsgSoc > 30 {
+		return nil, errors.New("minDsgSoc out of range. Range 0:30")
+	}
+	params := make(map[string]interface{})
+	params["minDsgSoc"] = minDsgSoc
+	return s.setParameter(ctx, "YJ751_PD_DSG_SOC_MIN_SET", params)
+}
+
+// SetAcChargingSettings AC charging current, A (chgC, range 1:30) and pause (chgPause, 0: charging, 1: paused)
+// { "id":123456789, "version":"1.0", "sn":"Y711ZAB59G170001", "cmdCode":"YJ751_PD_AC_CHG_SET", "params":{ "chgC":20, "chgPause":0 } }
+func (s *DeltaProUltra) SetAcChargingSettings(ctx context.Context, chgC int, paused bool) (*CmdSetResponse, error) {
+	if chgC < 1 || chgC > 30 {
+		return nil, errors.New("chgC out of range. Range 1:30")
+	}
+	params := make(map[string]interface{})
+	params["chgC"] = chgC
+	params["chgPause"] = boolFlag(paused)
+	return s.setParameter(ctx, "YJ751_PD_AC_CHG_SET", params)
+}
+
+// SetBackupReserve Energy management: the backup reserve switch and level (energyBackupStartSoc, range 5:100)
+// { "id":123456789, "version":"1.0", "sn":"Y711ZAB59G170001", "cmdCode":"YJ751_PD_ENERGY_BACKUP_SET", "params":{ "energyBackupEn":1, "energyBackupStartSoc":50 } }
+func (s *DeltaProUltra) SetBackupReserve(ctx context.Context, enabled SettingSwitcher, soc int) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("energyBackupEn out of range. Range 0:1")
+	}
+	if soc < 5 || soc > 100 {
+		return nil, errors.New("energyBackupStartSoc out of range. Range 5:100")
+	}
+	params := make(map[string]interface{})
+	params["energyBackupEn"] = enabled
+	params["energyBackupStartSoc"] = soc
+	return s.setParameter(ctx, "YJ751_PD_ENERGY_BACKUP_SET", params)
+}
+
+// SetAcStandByTime AC standby time, minutes (0: never)
+// { "id":123456789, "version":"1.0", "sn":"Y711ZAB59G170001", "cmdCode":"YJ751_PD_AC_STANDBY_TIME_SET", "params":{ "acStandbyMin":720 } }
+func (s *DeltaProUltra) SetAcStandByTime(ctx context.Context, minutes int) (*CmdSetResponse, error) {
+	if minutes < 0 {
+		return nil, errors.New("acStandbyMin must be positive")
+	}
+	params := make(map[string]interface{})
+	params["acStandbyMin"] = minutes
+	return s.setParameter(ctx, "YJ751_PD_AC_STANDBY_TIME_SET", params)
+}
+
+func (s *DeltaProUltra) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
+	return s.c.GetDeviceParameters(ctx, s.sn, params)
+}
+
+func (s *DeltaProUltra) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
+	return s.c.GetDeviceAllParameters(ctx, s.sn)
+}
+
+func (s *DeltaProUltra) setParameter(ctx context.Context, cmdCode string, params map[string]interface{}) (*CmdSetResponse, error) {
+	return s.c.SendCommand(ctx, Command{
+		Sn:      s.sn,
+		CmdCode: cmdCode,
+		Params:  params,
+	})
+}
@@ -0,0 +1,72 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeltaProUltraStatus(t *testing.T) {
+	client, _ := newTestClient(t, map[string]interface{}{
+		"hs_yj751_pd_appshow_addr.soc":         55,
+		"hs_yj751_pd_appshow_addr.wattsInSum":  1200,
+		"hs_yj751_pd_appshow_addr.inHvMpptPwr": 1200,
+		"hs_yj751_pd_backend_addr.chgMaxSoc":   100,
+		"hs_yj751_bms_slave_addr.1.soc":        50,
+		"hs_yj751_bms_slave_addr.1.vol":        51200,
+		"hs_yj751_bms_slave_addr.0.soc":        60,
+		"hs_yj751_bms_slave_addr.0.inputWatts": 600,
+	})
+	status, err := client.GetDeltaProUltra("Y711ZAB59G170001").GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Soc != 55 || status.InputWatts != 1200 || status.Inverter.SolarHvWatts != 1200 || status.MaxChargeSoc != 100 {
+		t.Errorf("unexpected status %+v", *status)
+	}
+	expected := []DeltaProUltraBattery{{Index: 0, Soc: 60, Watts: 600}, {Index: 1, Soc: 50, Voltage: 51.2}}
+	if len(status.Batteries) != 2 || status.Batteries[0] != expected[0] || status.Batteries[1] != expected[1] {
+		t.Errorf("got %+v, expected %+v", status.Batteries, expected)
+	}
+}
+
+func TestDeltaProUltraSetters(t *testing.T) {
+	client, last := newTestClient(t, nil)
+	ps := client.GetDeltaProUltra("Y711ZAB59G170001")
+
+	runSetterTests(t, last, []setterTest{
+		{
+			name:    "Discharge limit",
+			call:    func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetMinDischargeSoc(ctx, 10) },
+			cmdCode: "YJ751_PD_DSG_SOC_MIN_SET",
+			params:  map[string]interface{}{"minDsgSoc": float64(10)},
+		},
+		{
+			name: "AC output with X-Boost",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return ps.SetAcOutput(ctx, SettingEnabled, SettingDisabled)
+			},
+			cmdCode: "YJ751_PD_AC_DSG_SET",
+			params:  map[string]interface{}{"enable": float64(1), "xboost": float64(0)},
+		},
+		{
+			name: "Backup reserve",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return ps.SetBackupReserve(ctx, SettingEnabled, 50)
+			},
+			cmdCode: "YJ751_PD_ENERGY_BACKUP_SET",
+			params:  map[string]interface{}{"energyBackupEn": float64(1), "energyBackupStartSoc": float64(50)},
+		},
+		{
+			name: "Backup reserve out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetBackupReserve(ctx, SettingEnabled, 2) },
+		},
+		{
+			name: "Charge limit out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetMaxChargeSoc(ctx, 40) },
+		},
+		{
+			name: "AC charging current out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetAcChargingSettings(ctx, 31, false) },
+		},
+	})
+}
@@ -0,0 +1,128 @@
+package ecoflow
+
+import (
+	"context"
+	"strings"
+)
+
+// DeviceType is the kind of Ecoflow device, it defines which API (PowerStation, SmartPlug, etc.) can be used with the device
+type DeviceType string
+
+const (
+	DeviceTypeUnknown                  DeviceType = "unknown"
+	DeviceTypePowerStation             DeviceType = "power_station"
+	DeviceTypePowerStationPro          DeviceType = "power_station_pro"
+	DeviceTypePowerStation3            DeviceType = "power_station_3"
+	DeviceTypeDeltaPro3                DeviceType = "delta_pro_3"
+	DeviceTypeDeltaProUltra            DeviceType = "delta_pro_ultra"
+	DeviceTypePowerKit                 DeviceType = "power_kit"
+	DeviceTypePowerOcean               DeviceType = "power_ocean"
+	DeviceTypePowerStreamMicroInverter DeviceType = "powerstream"
+	DeviceTypeSmartHomePanel           DeviceType = "smart_home_panel"
+	DeviceTypeSmartHomePanel2          DeviceType = "smart_home_panel_2"
+	DeviceTypeSmartPlug                DeviceType = "smart_plug"
+	DeviceTypeWaveAirConditioner       DeviceType = "wave"
+	DeviceTypeGlacier                  DeviceType = "glacier"
+	DeviceTypeSmartGenerator           DeviceType = "smart_generator"
+	DeviceTypeAlternatorCharger        DeviceType = "alternator_charger"
+)
+
+// Device is a linked device with the inferred device type
+type Device struct {
+	DeviceInfo
+	Type DeviceType
+}
+
+// IsOnline returns true if the device is connected to the Ecoflow cloud
+func (d Device) IsOnline() bool {
+	return d.Online == 1
+}
+
+// productNamePrefixes maps the beginning of the product name (upper case) returned by the device list API to a device type.
+// The order matters: more specific names must be checked first (e.g. "DELTA PRO" before "DELTA")
+var productNamePrefixes = []struct {
+	prefix     string
+	deviceType DeviceType
+}{
+	{"DELTA PRO ULTRA", DeviceTypeDeltaProUltra},
+	{"DELTA PRO 3", DeviceTypeDeltaPro3},
+	{"DELTA PRO", DeviceTypePowerStationPro},
+	{"DELTA 3", DeviceTypePowerStation3},
+	{"RIVER 3", DeviceTypePowerStation3},
+	{"DELTA", DeviceTypePowerStation},
+	{"RIVER", DeviceTypePowerStation},
+	{"POWER KITS", DeviceTypePowerKit},
+	{"POWEROCEAN", DeviceTypePowerOcean},
+	{"POWERSTREAM", DeviceTypePowerStreamMicroInverter},
+	{"SMART HOME PANEL 2", DeviceTypeSmartHomePanel2},
+	{"SMART HOME PANEL", DeviceTypeSmartHomePanel},
+	{"SMART PLUG", DeviceTypeSmartPlug},
+	{"WAVE", DeviceTypeWaveAirConditioner},
+	{"GLACIER", DeviceTypeGlacier},
+	{"SMART GENERATOR", DeviceTypeSmartGenerator},
+	{"ALTERNATOR CHARGER", DeviceTypeAlternatorCharger},
+}
+
+// snPrefixes maps the beginning of the serial number to a device type. It's used when the product name is not available.
+// The prefixes are taken from the serial numbers in Ecoflow documentation and from real devices
+var snPrefixes = []struct {
+	prefix     string
+	deviceType DeviceType
+}{
+	{"R331", DeviceTypePowerStation},  // Delta 2
+	{"R351", DeviceTypePowerStation},  // Delta 2 Max
+	{"R601", DeviceTypePowerStation},  // River 2
+	{"R611", DeviceTypePowerStation},  // River 2 Max
+	{"R621", DeviceTypePowerStation},  // River 2 Pro
+	{"R651", DeviceTypePowerStation3}, // River 3
+	{"P351", DeviceTypePowerStation3}, // Delta 3 Plus
+	{"DAEB", DeviceTypePowerStation},  // Delta Max
+	{"DCAB", DeviceTypePowerStationPro},
+	{"MR51", DeviceTypeDeltaPro3},
+	{"Y711", DeviceTypeDeltaProUltra},
+	{"M106", DeviceTypePowerKit},
+	{"HJ31", DeviceTypePowerOcean},
+	{"HW51", DeviceTypePowerStreamMicroInverter},
+	{"HW52", DeviceTypeSmartPlug},
+	{"SP10", DeviceTypeSmartHomePanel},
+	{"HD31", DeviceTypeSmartHomePanel2},
+	{"KT21", DeviceTypeWaveAirConditioner},
+	{"BX11", DeviceTypeGlacier},
+	{"DGEB", DeviceTypeSmartGenerator},
+	{"F371", DeviceTypeAlternatorCharger},
+}
+
+// InferDeviceType returns the device type using the product name (if it's not empty) or the serial number.
+// DeviceTypeUnknown is returned if the device type can't be inferred
+func InferDeviceType(sn, productName string) DeviceType {
+	name := strings.ToUpper(strings.TrimSpace(productName))
+	if name != "" {
+		for _, p := range productNamePrefixes {
+			if strings.HasPrefix(name, p.prefix) {
+				return p.deviceType
+			}
+		}
+	}
+	for _, p := range snPrefixes {
+		if strings.HasPrefix(sn, p.prefix) {
+			return p.deviceType
+		}
+	}
+	return DeviceTypeUnknown
+}
+
+// Devices returns the list of linked devices (see GetDeviceList) with the inferred device type
+func (c *Client) Devices(ctx context.Context) ([]Device, error) {
+	resp, err := c.GetDeviceList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]Device, 0, len(resp.Devices))
+	for _, d := range resp.Devices {
+		devices = append(devices, Device{
+			DeviceInfo: d,
+			Type:       InferDeviceType(d.SN, d.ProductName),
+		})
+	}
+	return devices, nil
+}
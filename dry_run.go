@@ -0,0 +1,137 @@
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// In the dry-run mode the set commands are validated, built and signed, but not sent to the device.
+// The signed requests are returned in CmdSetResponse.DryRun, e.g.:
+//
+//	resp, err := client.GetPowerStation(sn).SetAcChargingSettings(ecoflow.ContextWithDryRun(ctx), 400, ecoflow.SettingEnabled)
+//	fmt.Println(string(resp.DryRun[0].Body))
+//
+// The dry-run mode can be enabled for all the commands of the client (WithDryRun) or for a call (ContextWithDryRun).
+// Some wrappers send several commands or don't return CmdSetResponse (e.g. WaveAirConditioner.SetTarget), all the
+// requests made with the context of ContextWithDryRun are collected and returned by DryRunRequests.
+// Only the set commands are affected, the parameters are still read from the device
+
+const (
+	dryRunMessage = "dry run"
+	dryRunPublish = "PUBLISH" // the method of the requests published to the MQTT broker
+)
+
+type dryRunKey struct{}
+
+// dryRunRecorder collects the requests made with the dry-run context
+type dryRunRecorder struct {
+	mu       sync.Mutex
+	requests []*DryRunRequest
+}
+
+// WithDryRun enables the dry-run mode for all the set commands of the client
+func WithDryRun() func(client *Client) {
+	return func(s *Client) {
+		s.dryRun = true
+	}
+}
+
+// ContextWithDryRun returns the context that enables the dry-run mode for the set commands called with it
+func ContextWithDryRun(ctx context.Context) context.Context {
+	if IsDryRun(ctx) {
+		return ctx
+	}
+	return context.WithValue(ctx, dryRunKey{}, &dryRunRecorder{})
+}
+
+// IsDryRun returns true if the dry-run mode is enabled by ContextWithDryRun
+func IsDryRun(ctx context.Context) bool {
+	_, ok := ctx.Value(dryRunKey{}).(*dryRunRecorder)
+	return ok
+}
+
+// DryRunRequests returns all the requests made with the context of ContextWithDryRun in the order they were made
+func DryRunRequests(ctx context.Context) []*DryRunRequest {
+	recorder, ok := ctx.Value(dryRunKey{}).(*dryRunRecorder)
+	if !ok {
+		return nil
+	}
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return append([]*DryRunRequest(nil), recorder.requests...)
+}
+
+// DryRunRequest is the signed request that would have been sent to Ecoflow REST API,
+// or the message that would have been published to the MQTT broker (Method is "PUBLISH", Url is the topic)
+type DryRunRequest struct {
+	Method string `json:"method"`
+	Url    string `json:"url"`
+	// Headers are the content type and the sign headers (accessKey, nonce, timestamp, sign)
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+func (c *Client) isDryRun(ctx context.Context) bool {
+	return c.dryRun || IsDryRun(ctx)
+}
+
+// recordDryRun adds the request to the requests of the dry-run context
+func recordDryRun(ctx context.Context, req *DryRunRequest) {
+	if recorder, ok := ctx.Value(dryRunKey{}).(*dryRunRecorder); ok {
+		recorder.mu.Lock()
+		recorder.requests = append(recorder.requests, req)
+		recorder.mu.Unlock()
+	}
+}
+
+// dryRunResponse signs the request and returns it as a successful response without sending it
+func dryRunResponse(ctx context.Context, r *HttpRequest) (*CmdSetResponse, error) {
+	httpReq, err := r.Sign(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newDryRunRequest(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	recordDryRun(ctx, req)
+	return &CmdSetResponse{Code: "0", Message: dryRunMessage, DryRun: []*DryRunRequest{req}}, nil
+}
+
+// joinDryRun adds the dry-run requests of the previous responses to the response of the last command,
+// it's used by the wrappers that send several commands
+func joinDryRun(resp *CmdSetResponse, err error, previous ...*CmdSetResponse) (*CmdSetResponse, error) {
+	if resp == nil || resp.DryRun == nil {
+		return resp, err
+	}
+	var requests []*DryRunRequest
+	for _, p := range previous {
+		if p != nil {
+			requests = append(requests, p.DryRun...)
+		}
+	}
+	resp.DryRun = append(requests, resp.DryRun...)
+	return resp, err
+}
+
+func newDryRunRequest(httpReq *http.Request) (*DryRunRequest, error) {
+	req := &DryRunRequest{
+		Method:  httpReq.Method,
+		Url:     httpReq.URL.String(),
+		Headers: make(map[string]string, len(httpReq.Header)),
+	}
+	for k := range httpReq.Header {
+		req.Headers[k] = httpReq.Header.Get(k)
+	}
+	if httpReq.Body != nil {
+		body, err := io.ReadAll(httpReq.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	return req, nil
+}
@@ -0,0 +1,142 @@
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	client, last := newTestClient(t, nil)
+	ps := client.GetPowerStation("R331ZEB4ZEAL0528")
+	ctx := ContextWithDryRun(context.Background())
+
+	resp, err := ps.SetAcChargingSettings(ctx, 400, SettingEnabled)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *last != nil {
+		t.Fatalf("the request is sent in the dry-run mode: %v", *last)
+	}
+	if resp.Code != "0" || len(resp.DryRun) != 1 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	req := resp.DryRun[0]
+	if req.Method != http.MethodPut || !strings.HasSuffix(req.Url, setDeviceFunctionUrl) {
+		t.Errorf("unexpected request %s %s", req.Method, req.Url)
+	}
+	for _, h := range []string{accessKeyHeader, nonceHeader, timestampHeader, signHeader} {
+		if req.Headers[http.CanonicalHeaderKey(h)] == "" {
+			t.Errorf("%s header is not set: %v", h, req.Headers)
+		}
+	}
+	var body map[string]interface{}
+	if err = json.Unmarshal(req.Body, &body); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	params := body["params"].(map[string]interface{})
+	if body["operateType"] != "acChgCfg" || params["chgWatts"] != float64(400) {
+		t.Errorf("unexpected body %v", body)
+	}
+
+	// the validation errors are returned in the dry-run mode
+	if _, err = ps.SetAcChargingSettings(ctx, -1, SettingEnabled); err == nil {
+		t.Errorf("expected validation error")
+	}
+
+	// the context without the flag sends the request
+	resp, err = ps.SetAcChargingSettings(context.Background(), 400, SettingEnabled)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *last == nil || resp.DryRun != nil {
+		t.Errorf("the request is not sent")
+	}
+}
+
+func TestWithDryRun(t *testing.T) {
+	// the client would fail to send the request to the closed port
+	client := NewEcoflowClient("access", "secret", WithBaseUrl("http://127.0.0.1:0"), WithDryRun())
+
+	resp, err := client.SendCommand(context.Background(), Command{Sn: "HD31ZAS4HGB10001", CmdFunc: 12, Params: map[string]interface{}{"backupReserveSoc": 30}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.DryRun) != 1 || !strings.Contains(string(resp.DryRun[0].Body), `"backupReserveSoc":30`) {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestDryRunMultipleCommands(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{
+		"pd.powerMode": 2,
+		"pd.mainMode":  0,
+		"pd.setTemp":   22,
+		"pd.fanValue":  1,
+	})
+
+	// all the commands of the wrapper are returned
+	ctx := ContextWithDryRun(context.Background())
+	battery := client.GetPowerKit("M106ZAB4Z000001F", "").GetBattery("M102Z3B4ZEA70076")
+	resp, err := battery.SetGeneratorAutoStart(ctx, 20, 60)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.DryRun) != 2 || !strings.Contains(string(resp.DryRun[0].Body), `"oilStartDownLimit"`) ||
+		!strings.Contains(string(resp.DryRun[1].Body), `"oilStopUpLimit"`) {
+		t.Errorf("unexpected dry-run requests %+v", resp.DryRun)
+	}
+	if len(DryRunRequests(ctx)) != 2 {
+		t.Errorf("unexpected recorded requests %+v", DryRunRequests(ctx))
+	}
+
+	// the state of the air conditioner isn't verified, the commands would have changed it
+	ctx = ContextWithDryRun(context.Background())
+	wave := client.GetWaveAirConditioner("KT21ZCH2ZF170012")
+	status, err := wave.SetTarget(ctx, ConditionerMainModeHeat, 24, ConditionerWindSpeedHigh)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.PowerMode != ConditionerPowerModeStartup || status.MainMode != ConditionerMainModeHeat || status.SetTemperature != 24 || status.Fan != ConditionerWindSpeedHigh {
+		t.Errorf("unexpected status %+v", *status)
+	}
+	requests := DryRunRequests(ctx)
+	if len(requests) != 4 {
+		t.Fatalf("unexpected recorded requests %+v", requests)
+	}
+	for i, operateType := range []string{"powerMode", "mainMode", "setTemp", "fanValue"} {
+		if !strings.Contains(string(requests[i].Body), `"operateType":"`+operateType+`"`) {
+			t.Errorf("unexpected request %d: %s", i, requests[i].Body)
+		}
+	}
+	if *last != nil {
+		t.Errorf("the request is sent in the dry-run mode: %v", *last)
+	}
+}
+
+func TestDryRunPublishCommand(t *testing.T) {
+	broker := &fakeMqttClient{acked: true}
+	client := &MqttClient{Client: broker, connectionConfig: &MqttConnectionConfig{UserId: "1234"}}
+	ctx := ContextWithDryRun(context.Background())
+
+	cmd := Command{Sn: "R331ZEB4ZEAL0528", OperateType: "acOutCfg", ModuleType: ModuleTypeInv, Params: map[string]interface{}{"enabled": 1}}
+	payload, err := client.PublishCommand(ctx, cmd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if broker.published != nil {
+		t.Fatalf("the command is published in the dry-run mode")
+	}
+	requests := DryRunRequests(ctx)
+	if len(requests) != 1 || requests[0].Method != dryRunPublish || requests[0].Url != "/app/1234/R331ZEB4ZEAL0528/thing/property/set" ||
+		string(requests[0].Body) != string(payload) {
+		t.Errorf("unexpected recorded requests %+v", requests)
+	}
+
+	client.dryRun = true
+	if _, err = client.PublishCommand(context.Background(), cmd); err != nil || broker.published != nil {
+		t.Errorf("the command is published in the dry-run mode: %v", err)
+	}
+}
@@ -0,0 +1,290 @@
+// Package energy integrates instantaneous power readings of the devices (e.g. inv.outputWatts, mppt.inWatts,
+// Smart Home Panel circuit powers) into energy totals (kWh) per device, channel and day, week or month.
+//
+// The power is integrated with the trapezoidal rule between two consecutive readings of a channel. The interval is not
+// integrated if the readings are more than MaxGap apart (the device was offline, the process wasn't running, etc.), if
+// the device clock went backwards (e.g. after the device restart) the integration starts again from the new reading.
+// The totals and the last readings are saved to a JSON file, so they survive the process restarts.
+package energy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+const (
+	defaultMaxGap    = 5 * time.Minute
+	defaultRetention = 400 * 24 * time.Hour
+	dayFormat        = "2006-01-02"
+)
+
+// Period is the period of the energy totals
+type Period int
+
+const (
+	PeriodDay Period = iota
+	// PeriodWeek is the week from Monday to Sunday
+	PeriodWeek
+	PeriodMonth
+)
+
+type Options struct {
+	// Channels overrides the channels of the device types, DefaultChannels are used for other device types
+	Channels map[ecoflow.DeviceType][]Channel
+	// MaxGap is the maximum interval between two readings that is integrated, 5 minutes by default
+	MaxGap time.Duration
+	// Location defines the day boundaries, time.Local by default
+	Location *time.Location
+	// Retention is how long the daily totals are kept, 400 days by default
+	Retention time.Duration
+	// StatePath is the JSON file to keep the totals across restarts. The state is loaded by NewAccumulator
+	// and saved by Save or Run. The state is not persisted if empty
+	StatePath string
+}
+
+// Accumulator integrates the power readings into energy totals. It's safe for concurrent use
+type Accumulator struct {
+	options Options
+	mu      sync.Mutex
+	state   state
+}
+
+type state struct {
+	// Devices is sn -> channel name -> channel state
+	Devices map[string]map[string]*channelState `json:"devices"`
+}
+
+type channelState struct {
+	LastTime  time.Time `json:"lastTime"`
+	LastWatts float64   `json:"lastWatts"`
+	// Days is the energy in Wh per day (yyyy-mm-dd in Options.Location)
+	Days map[string]float64 `json:"days"`
+}
+
+// NewAccumulator creates the accumulator and loads the state from Options.StatePath if the file exists
+func NewAccumulator(options Options) (*Accumulator, error) {
+	if options.MaxGap <= 0 {
+		options.MaxGap = defaultMaxGap
+	}
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+	if options.Retention <= 0 {
+		options.Retention = defaultRetention
+	}
+	a := &Accumulator{options: options, state: state{Devices: make(map[string]map[string]*channelState)}}
+	if options.StatePath == "" {
+		return a, nil
+	}
+
+	data, err := os.ReadFile(options.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &a.state); err != nil {
+		return nil, err
+	}
+	if a.state.Devices == nil {
+		a.state.Devices = make(map[string]map[string]*channelState)
+	}
+	return a, nil
+}
+
+// Write integrates the power channels of the snapshot. The channels missing in the snapshot (e.g. in partial MQTT
+// snapshots) are not changed. It can be used as ecoflow.PollerConfig.OnSnapshot handler or with
+// ecoflow.MqttClient.SubscribeForSnapshots
+func (a *Accumulator) Write(snapshot ecoflow.DeviceSnapshot) {
+	channels, ok := a.options.Channels[snapshot.DeviceType]
+	if !ok {
+		channels = DefaultChannels(snapshot.DeviceType)
+	}
+	if len(channels) == 0 {
+		return
+	}
+	ts := snapshot.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	device, ok := a.state.Devices[snapshot.Sn]
+	if !ok {
+		device = make(map[string]*channelState)
+		a.state.Devices[snapshot.Sn] = device
+	}
+	for _, c := range channels {
+		watts, ok := c.watts(snapshot.Params)
+		if !ok {
+			continue
+		}
+		cs, ok := device[c.Name]
+		if !ok {
+			cs = &channelState{Days: make(map[string]float64)}
+			device[c.Name] = cs
+		}
+		a.add(cs, ts, watts)
+	}
+}
+
+// add integrates the interval between the last reading of the channel and the new one
+func (a *Accumulator) add(cs *channelState, ts time.Time, watts float64) {
+	if !cs.LastTime.IsZero() {
+		elapsed := ts.Sub(cs.LastTime)
+		if elapsed <= 0 && elapsed > -a.options.MaxGap {
+			// duplicate or out of order reading (e.g. REST poll and MQTT message received at the same time)
+			return
+		}
+		if elapsed > 0 && elapsed <= a.options.MaxGap {
+			a.integrate(cs, cs.LastTime, cs.LastWatts, ts, watts)
+		}
+	}
+	cs.LastTime = ts
+	cs.LastWatts = watts
+}
+
+// integrate adds the energy between the two readings to the days, the interval is split at the day boundaries
+func (a *Accumulator) integrate(cs *channelState, t0 time.Time, p0 float64, t1 time.Time, p1 float64) {
+	interval := t1.Sub(t0)
+	power := func(t time.Time) float64 {
+		return p0 + (p1-p0)*float64(t.Sub(t0))/float64(interval)
+	}
+	for start := t0; start.Before(t1); {
+		end := a.startOfDay(start).AddDate(0, 0, 1)
+		if end.After(t1) {
+			end = t1
+		}
+		cs.Days[start.In(a.options.Location).Format(dayFormat)] += (power(start) + power(end)) / 2 * end.Sub(start).Hours()
+		start = end
+	}
+}
+
+func (a *Accumulator) startOfDay(t time.Time) time.Time {
+	y, m, d := t.In(a.options.Location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, a.options.Location)
+}
+
+// bounds returns the first day and the day after the last day of the period that contains t
+func (a *Accumulator) bounds(period Period, t time.Time) (time.Time, time.Time) {
+	day := a.startOfDay(t)
+	switch period {
+	case PeriodWeek:
+		// time.Sunday is 0, the week starts on Monday
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	case PeriodMonth:
+		start := day.AddDate(0, 0, 1-day.Day())
+		return start, start.AddDate(0, 1, 0)
+	default:
+		return day, day.AddDate(0, 0, 1)
+	}
+}
+
+// Energy returns the energy in kWh of the device channel for the period that contains t
+func (a *Accumulator) Energy(sn, channel string, period Period, t time.Time) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cs, ok := a.state.Devices[sn][channel]
+	if !ok {
+		return 0
+	}
+	return a.sum(cs, period, t)
+}
+
+// Totals returns the energy in kWh of all device channels for the period that contains t
+func (a *Accumulator) Totals(sn string, period Period, t time.Time) map[string]float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	totals := make(map[string]float64)
+	for name, cs := range a.state.Devices[sn] {
+		totals[name] = a.sum(cs, period, t)
+	}
+	return totals
+}
+
+// Devices returns serial numbers of the devices that have energy totals
+func (a *Accumulator) Devices() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	devices := make([]string, 0, len(a.state.Devices))
+	for sn := range a.state.Devices {
+		devices = append(devices, sn)
+	}
+	return devices
+}
+
+func (a *Accumulator) sum(cs *channelState, period Period, t time.Time) float64 {
+	start, end := a.bounds(period, t)
+	var wh float64
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		wh += cs.Days[day.Format(dayFormat)]
+	}
+	return wh / 1000
+}
+
+// Save removes the totals older than Options.Retention and writes the state to Options.StatePath
+func (a *Accumulator) Save() error {
+	if a.options.StatePath == "" {
+		return nil
+	}
+
+	a.mu.Lock()
+	oldest := a.startOfDay(time.Now().Add(-a.options.Retention)).Format(dayFormat)
+	for _, device := range a.state.Devices {
+		for _, cs := range device {
+			for day := range cs.Days {
+				if day < oldest {
+					delete(cs.Days, day)
+				}
+			}
+		}
+	}
+	data, err := json.Marshal(a.state)
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// the state is written to a temporary file first, so the previous state is not lost if the process is killed
+	tmp, err := os.CreateTemp(filepath.Dir(a.options.StatePath), filepath.Base(a.options.StatePath)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), a.options.StatePath)
+}
+
+// Run saves the state every interval until the context is cancelled. The state is saved before returning
+func (a *Accumulator) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return a.Save()
+		case <-ticker.C:
+			if err := a.Save(); err != nil {
+				slog.Error("Unable to save energy totals", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package energy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+// Channel names used by DefaultChannels
+const (
+	ChannelSolarIn = "solar_in"
+	ChannelGridIn  = "grid_in"
+	ChannelAcOut   = "ac_out"
+)
+
+const (
+	shpLoadCircuits    = 10
+	shpStandbyChannels = 2
+	shp2LoadCircuits   = 12
+)
+
+// Channel is a power parameter of a device that is integrated into energy
+type Channel struct {
+	// Name is the name of the channel in the totals, e.g. "solar_in"
+	Name string
+	// Key is the power parameter name, e.g. "mppt.inWatts". An element of an array parameter is selected with
+	// the index in square brackets, e.g. "wattInfo.chWatt[2]"
+	Key string
+	// Scale converts the parameter value to watts (e.g. 0.1 for the parameters in 0.1W), 1 is used if 0
+	Scale float64
+}
+
+// DefaultChannels returns the power channels of the device type:
+//   - power stations: solar input, grid (AC) input and AC output
+//   - PowerOcean: solar input and house load
+//   - PowerStream: solar input of both PV inputs and inverter output
+//   - Smart Home Panel: load circuits (circuit_1 - circuit_10) and standby channels (standby_1, standby_2)
+//   - Smart Home Panel 2: load circuits (circuit_1 - circuit_12)
+//   - Smart Plug: load
+func DefaultChannels(deviceType ecoflow.DeviceType) []Channel {
+	switch deviceType {
+	case ecoflow.DeviceTypePowerStation, ecoflow.DeviceTypePowerStationPro:
+		return []Channel{
+			{Name: ChannelSolarIn, Key: "mppt.inWatts"},
+			{Name: ChannelGridIn, Key: "inv.inputWatts"},
+			{Name: ChannelAcOut, Key: "inv.outputWatts"},
+		}
+	case ecoflow.DeviceTypePowerStation3:
+		return []Channel{
+			{Name: ChannelSolarIn, Key: "powGetPv"},
+			{Name: ChannelGridIn, Key: "powGetAcIn"},
+			{Name: ChannelAcOut, Key: "powGetAcOut"},
+		}
+	case ecoflow.DeviceTypePowerOcean:
+		return []Channel{
+			{Name: ChannelSolarIn, Key: "mpptPwr"},
+			{Name: "house_load", Key: "sysLoadPwr"},
+		}
+	case ecoflow.DeviceTypePowerStreamMicroInverter:
+		return []Channel{
+			{Name: ChannelSolarIn + "_pv1", Key: "20_1.pv1InputWatts", Scale: 0.1},
+			{Name: ChannelSolarIn + "_pv2", Key: "20_1.pv2InputWatts", Scale: 0.1},
+			{Name: ChannelAcOut, Key: "20_1.invOutputWatts", Scale: 0.1},
+		}
+	case ecoflow.DeviceTypeSmartHomePanel:
+		channels := make([]Channel, 0, shpLoadCircuits+shpStandbyChannels)
+		for i := 0; i < shpLoadCircuits; i++ {
+			channels = append(channels, Channel{Name: fmt.Sprintf("circuit_%d", i+1), Key: fmt.Sprintf("wattInfo.chWatt[%d]", i)})
+		}
+		for i := 0; i < shpStandbyChannels; i++ {
+			channels = append(channels, Channel{Name: fmt.Sprintf("standby_%d", i+1), Key: fmt.Sprintf("wattInfo.chWatt[%d]", shpLoadCircuits+i)})
+		}
+		return channels
+	case ecoflow.DeviceTypeSmartHomePanel2:
+		channels := make([]Channel, 0, shp2LoadCircuits)
+		for i := 0; i < shp2LoadCircuits; i++ {
+			channels = append(channels, Channel{Name: fmt.Sprintf("circuit_%d", i+1), Key: fmt.Sprintf("loadInfo.hall1Watt[%d]", i)})
+		}
+		return channels
+	case ecoflow.DeviceTypeSmartPlug:
+		return []Channel{{Name: "load", Key: "2_1.watts", Scale: 0.1}}
+	}
+	return nil
+}
+
+// watts returns the power of the channel in watts, false if the snapshot doesn't contain the value
+func (c Channel) watts(params map[string]interface{}) (float64, bool) {
+	key, index := c.Key, -1
+	if strings.HasSuffix(key, "]") {
+		if i := strings.LastIndex(key, "["); i > 0 {
+			n, err := strconv.Atoi(key[i+1 : len(key)-1])
+			if err != nil || n < 0 {
+				return 0, false
+			}
+			key, index = key[:i], n
+		}
+	}
+
+	v, ok := params[key]
+	if !ok {
+		return 0, false
+	}
+	if index >= 0 {
+		values, ok := v.([]interface{})
+		if !ok || index >= len(values) {
+			return 0, false
+		}
+		v = values[index]
+	}
+	value, ok := ecoflow.NumericValue(v)
+	if !ok {
+		return 0, false
+	}
+	if c.Scale != 0 {
+		value *= c.Scale
+	}
+	return value, true
+}
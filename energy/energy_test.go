@@ -0,0 +1,113 @@
+package energy
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+const sn = "R331ZEB4ZEAL0528"
+
+var start = time.Date(2024, 3, 31, 23, 58, 0, 0, time.UTC)
+
+func reading(at time.Duration, acOut float64) ecoflow.DeviceSnapshot {
+	return ecoflow.DeviceSnapshot{
+		Sn:         sn,
+		DeviceType: ecoflow.DeviceTypePowerStation,
+		Timestamp:  start.Add(at),
+		Partial:    true,
+		Params:     map[string]interface{}{"inv.outputWatts": acOut},
+	}
+}
+
+func assertEnergy(t *testing.T, name string, actual, expected float64) {
+	t.Helper()
+	if math.Abs(actual-expected) > 1e-9 {
+		t.Errorf("%s: got %v kWh, expected %v kWh", name, actual, expected)
+	}
+}
+
+func TestAccumulator(t *testing.T) {
+	a, err := NewAccumulator(Options{Location: time.UTC})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a.Write(reading(0, 0))
+	// 0W -> 600W during 1 minute: 5Wh
+	a.Write(reading(time.Minute, 600))
+	// out of order reading is ignored
+	a.Write(reading(30*time.Second, 6000))
+	// 600W during 2 minutes, the half is after midnight: 10Wh + 10Wh
+	a.Write(reading(3*time.Minute, 600))
+	// the gap is longer than MaxGap and is not integrated
+	a.Write(reading(20*time.Minute, 1200))
+	// 1200W during 1 minute: 20Wh
+	a.Write(reading(21*time.Minute, 1200))
+
+	assertEnergy(t, "March 31", a.Energy(sn, ChannelAcOut, PeriodDay, start), 0.015)
+	assertEnergy(t, "April 1", a.Energy(sn, ChannelAcOut, PeriodDay, start.Add(time.Hour)), 0.030)
+	// March 31 is Sunday, so both days are in different weeks
+	assertEnergy(t, "Week of April 1", a.Energy(sn, ChannelAcOut, PeriodWeek, start.Add(72*time.Hour)), 0.030)
+	assertEnergy(t, "March", a.Energy(sn, ChannelAcOut, PeriodMonth, start.AddDate(0, 0, -10)), 0.015)
+	assertEnergy(t, "Solar", a.Energy(sn, ChannelSolarIn, PeriodDay, start), 0)
+
+	// the device clock went back, the integration starts from the new reading
+	a.Write(reading(-time.Hour, 600))
+	a.Write(reading(-time.Hour+time.Minute, 600))
+	assertEnergy(t, "March 31 after clock reset", a.Energy(sn, ChannelAcOut, PeriodDay, start), 0.025)
+}
+
+func TestAccumulatorState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "energy.json")
+	a, err := NewAccumulator(Options{Location: time.UTC, StatePath: path, Retention: 100 * 365 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	a.Write(reading(0, 600))
+	a.Write(reading(time.Minute, 600))
+	if err = a.Save(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the integration continues from the last reading saved before the restart
+	restored, err := NewAccumulator(Options{Location: time.UTC, StatePath: path, Retention: 100 * 365 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restored.Write(reading(2*time.Minute, 600))
+	assertEnergy(t, "restored", restored.Energy(sn, ChannelAcOut, PeriodDay, start), 0.020)
+}
+
+func TestSmartHomePanelCircuits(t *testing.T) {
+	a, err := NewAccumulator(Options{Location: time.UTC})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	watts := func(w float64) []interface{} {
+		values := make([]interface{}, 12)
+		for i := range values {
+			values[i] = float64(0)
+		}
+		values[2] = w
+		return values
+	}
+	for i := 0; i < 2; i++ {
+		a.Write(ecoflow.DeviceSnapshot{
+			Sn:         "SP10ZAW5ZE9E0052",
+			DeviceType: ecoflow.DeviceTypeSmartHomePanel,
+			Timestamp:  start.Add(time.Duration(i) * time.Minute),
+			Params:     map[string]interface{}{"wattInfo.chWatt": watts(1200)},
+		})
+	}
+
+	totals := a.Totals("SP10ZAW5ZE9E0052", PeriodDay, start)
+	if len(totals) != 12 {
+		t.Errorf("expected 12 channels, got %v", totals)
+	}
+	assertEnergy(t, "circuit_3", totals["circuit_3"], 0.020)
+	assertEnergy(t, "circuit_1", totals["circuit_1"], 0)
+}
@@ -0,0 +1,396 @@
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+)
+
+// GenericDevice is a device that is not supported by the library, its commands and telemetry are described by DeviceSchema.
+// The commands are validated and built the same way as the hand-written wrappers do, e.g. the schema
+//
+//	{
+//	  "name": "Smart Generator",
+//	  "commands": [{ "name": "ecoMode", "operateType": "ecoMode", "moduleType": 1, "params": [{ "name": "ecoMode", "type": "int", "enum": [0, 1] }] }],
+//	  "telemetry": [{ "name": "fuel_level", "key": "pd.oilVal", "unit": "%" }]
+//	}
+//
+// sends { "id":"...", "sn":"...", "moduleType":1, "operateType":"ecoMode", "params":{ "ecoMode":1 } } for
+// SendCommand(ctx, "ecoMode", map[string]interface{}{"ecoMode": 1}).
+//
+// The YAML schemas are parsed by the yamlschema module (github.com/tess1o/go-ecoflow/yamlschema),
+// so the library doesn't depend on a YAML parser
+type GenericDevice struct {
+	c      *Client
+	sn     string
+	schema *DeviceSchema
+}
+
+// SchemaParamType is the type of the command parameter or the telemetry field
+type SchemaParamType string
+
+const (
+	SchemaParamTypeInt    SchemaParamType = "int"
+	SchemaParamTypeFloat  SchemaParamType = "float"
+	SchemaParamTypeBool   SchemaParamType = "bool"
+	SchemaParamTypeString SchemaParamType = "string"
+)
+
+// DeviceSchema is the declarative description of a device, see LoadDeviceSchema
+type DeviceSchema struct {
+	Name      string                 `json:"name" yaml:"name"`
+	Commands  []CommandSchema        `json:"commands" yaml:"commands"`
+	Telemetry []TelemetryFieldSchema `json:"telemetry" yaml:"telemetry"`
+}
+
+// CommandSchema is a set command. A command uses one of the addressing styles:
+//   - operateType and moduleType (PowerStation, Glacier, Wave, etc.)
+//   - cmdCode (Delta Pro Ultra)
+//   - cmdSet and id that are sent in the parameters, optionally with operateType "TCP" (Smart Home Panel, PowerStream, Delta Pro)
+//   - cmdFunc (and cmdId, 17 by default) of the cfg header (Delta Pro 3, River 3, Smart Home Panel 2)
+//
+// moduleType is used only by the operateType style, the schema is rejected if it's combined with the other styles
+type CommandSchema struct {
+	Name        string `json:"name" yaml:"name"`
+	OperateType string `json:"operateType,omitempty" yaml:"operateType,omitempty"`
+	ModuleType  int    `json:"moduleType,omitempty" yaml:"moduleType,omitempty"`
+	CmdCode     string `json:"cmdCode,omitempty" yaml:"cmdCode,omitempty"`
+	CmdSet      *int   `json:"cmdSet,omitempty" yaml:"cmdSet,omitempty"`
+	Id          *int   `json:"id,omitempty" yaml:"id,omitempty"`
+	CmdId       *int   `json:"cmdId,omitempty" yaml:"cmdId,omitempty"`
+	CmdFunc     *int   `json:"cmdFunc,omitempty" yaml:"cmdFunc,omitempty"`
+	// Fixed are the parameters sent with every command, e.g. {"isConfig": 1}
+	Fixed  map[string]interface{} `json:"fixed,omitempty" yaml:"fixed,omitempty"`
+	Params []ParamSchema          `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// ParamSchema is a parameter of the command. Min, Max and Enum are optional, all the parameters are required unless Optional is set
+type ParamSchema struct {
+	Name     string          `json:"name" yaml:"name"`
+	Type     SchemaParamType `json:"type" yaml:"type"`
+	Min      *float64        `json:"min,omitempty" yaml:"min,omitempty"`
+	Max      *float64        `json:"max,omitempty" yaml:"max,omitempty"`
+	Enum     []interface{}   `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Optional bool            `json:"optional,omitempty" yaml:"optional,omitempty"`
+}
+
+// TelemetryFieldSchema maps a device parameter (Key, e.g. "pd.soc") to the named field of the decoded status.
+// Type is float by default, the numeric values are multiplied by Scale (if it's not 0)
+type TelemetryFieldSchema struct {
+	Name  string          `json:"name" yaml:"name"`
+	Key   string          `json:"key" yaml:"key"`
+	Type  SchemaParamType `json:"type,omitempty" yaml:"type,omitempty"`
+	Scale float64         `json:"scale,omitempty" yaml:"scale,omitempty"`
+	Unit  string          `json:"unit,omitempty" yaml:"unit,omitempty"`
+}
+
+// LoadDeviceSchema reads the schema from the JSON file, see yamlschema.Load for the YAML files
+func LoadDeviceSchema(path string) (*DeviceSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDeviceSchemaJSON(data)
+}
+
+// ParseDeviceSchemaJSON parses and validates the JSON schema
+func ParseDeviceSchemaJSON(data []byte) (*DeviceSchema, error) {
+	var schema DeviceSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// Validate checks that the commands and the telemetry fields are complete and unique
+func (s *DeviceSchema) Validate() error {
+	names := make(map[string]bool)
+	for _, cmd := range s.Commands {
+		if cmd.Name == "" {
+			return errors.New("command name is empty")
+		}
+		if names[cmd.Name] {
+			return fmt.Errorf("command %s is duplicated", cmd.Name)
+		}
+		names[cmd.Name] = true
+		if err := cmd.validate(); err != nil {
+			return fmt.Errorf("command %s: %w", cmd.Name, err)
+		}
+	}
+	fields := make(map[string]bool)
+	for _, f := range s.Telemetry {
+		if f.Name == "" || f.Key == "" {
+			return errors.New("telemetry field name and key are required")
+		}
+		if fields[f.Name] {
+			return fmt.Errorf("telemetry field %s is duplicated", f.Name)
+		}
+		fields[f.Name] = true
+		if f.Type != "" && !validSchemaType(f.Type) {
+			return fmt.Errorf("telemetry field %s: unknown type %s", f.Name, f.Type)
+		}
+	}
+	return nil
+}
+
+// Command returns the command by name, false if there is no such command
+func (s *DeviceSchema) Command(name string) (*CommandSchema, bool) {
+	for i := range s.Commands {
+		if s.Commands[i].Name == name {
+			return &s.Commands[i], true
+		}
+	}
+	return nil, false
+}
+
+// Decode returns the telemetry fields found in the parameters (see GetAllParameters or DeviceSnapshot.Params) by their names.
+// The fields missing in the parameters or having a value of another type are skipped
+func (s *DeviceSchema) Decode(params map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, f := range s.Telemetry {
+		v, ok := params[f.Key]
+		if !ok {
+			continue
+		}
+		if f.Type == SchemaParamTypeString {
+			if str, ok := v.(string); ok {
+				result[f.Name] = str
+			}
+			continue
+		}
+		n, ok := NumericValue(v)
+		if !ok {
+			continue
+		}
+		if f.Scale != 0 {
+			n *= f.Scale
+		}
+		switch f.Type {
+		case SchemaParamTypeInt:
+			result[f.Name] = int(math.Round(n))
+		case SchemaParamTypeBool:
+			result[f.Name] = n != 0
+		default:
+			result[f.Name] = n
+		}
+	}
+	return result
+}
+
+func (c *CommandSchema) validate() error {
+	styles := 0
+	if c.CmdCode != "" {
+		styles++
+	}
+	if c.CmdSet != nil || c.Id != nil {
+		if c.CmdSet == nil || c.Id == nil {
+			return errors.New("cmdSet and id must be set together")
+		}
+		styles++
+	}
+	if c.CmdFunc != nil {
+		styles++
+	}
+	if c.OperateType != "" && c.CmdSet == nil {
+		styles++
+	}
+	if styles != 1 {
+		return errors.New("exactly one of operateType, cmdCode, cmdSet/id or cmdFunc must be set")
+	}
+	if c.CmdId != nil && c.CmdFunc == nil {
+		return errors.New("cmdId can be used only with cmdFunc")
+	}
+	if c.ModuleType != 0 && (c.OperateType == "" || c.CmdSet != nil) {
+		return errors.New("moduleType can be used only with operateType (without cmdSet)")
+	}
+	params := make(map[string]bool)
+	for _, p := range c.Params {
+		if p.Name == "" {
+			return errors.New("parameter name is empty")
+		}
+		if params[p.Name] {
+			return fmt.Errorf("parameter %s is duplicated", p.Name)
+		}
+		params[p.Name] = true
+		if !validSchemaType(p.Type) {
+			return fmt.Errorf("parameter %s: unknown type %s", p.Name, p.Type)
+		}
+		if p.Min != nil && p.Max != nil && *p.Min > *p.Max {
+			return fmt.Errorf("parameter %s: min is greater than max", p.Name)
+		}
+	}
+	return nil
+}
+
+// value converts the value to the parameter type and checks the range and the allowed values
+func (p *ParamSchema) value(v interface{}) (interface{}, error) {
+	var result interface{}
+	switch p.Type {
+	case SchemaParamTypeString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a string", p.Name)
+		}
+		result = s
+	case SchemaParamTypeBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a bool", p.Name)
+		}
+		result = b
+	default:
+		n, ok := schemaNumber(v)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a number", p.Name)
+		}
+		if (p.Min != nil && n < *p.Min) || (p.Max != nil && n > *p.Max) {
+			return nil, fmt.Errorf("%s out of range. Range %s:%s", p.Name, formatBound(p.Min), formatBound(p.Max))
+		}
+		if p.Type == SchemaParamTypeInt {
+			if n != math.Trunc(n) {
+				return nil, fmt.Errorf("%s must be an integer", p.Name)
+			}
+			result = int(n)
+		} else {
+			result = n
+		}
+	}
+	if len(p.Enum) > 0 && !slices.ContainsFunc(p.Enum, func(e interface{}) bool { return schemaEqual(e, result) }) {
+		return nil, fmt.Errorf("%s must be one of %v", p.Name, p.Enum)
+	}
+	return result, nil
+}
+
+func validSchemaType(t SchemaParamType) bool {
+	return t == SchemaParamTypeInt || t == SchemaParamTypeFloat || t == SchemaParamTypeBool || t == SchemaParamTypeString
+}
+
+// schemaNumber returns the numeric value, the named numeric types (e.g. SettingSwitcher) are supported
+func schemaNumber(v interface{}) (float64, bool) {
+	if _, ok := v.(bool); ok {
+		return 0, false
+	}
+	if n, ok := NumericValue(v); ok {
+		return n, true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// schemaEqual compares the enum item (int from YAML, float64 from JSON) with the value
+func schemaEqual(enum, value interface{}) bool {
+	if n, ok := schemaNumber(value); ok {
+		e, ok := schemaNumber(enum)
+		return ok && e == n
+	}
+	return enum == value
+}
+
+func formatBound(b *float64) string {
+	if b == nil {
+		return ""
+	}
+	return fmt.Sprint(*b)
+}
+
+func (d *GenericDevice) GetSn() string {
+	return d.sn
+}
+
+// Schema returns the schema of the device
+func (d *GenericDevice) Schema() *DeviceSchema {
+	return d.schema
+}
+
+// BuildCommand validates the values and returns the request of the command, the request can be sent by
+// Client.SetDeviceParameter or published to the MQTT set topic
+func (d *GenericDevice) BuildCommand(name string, values map[string]interface{}) (map[string]interface{}, error) {
+	cmd, ok := d.schema.Command(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown command %s", name)
+	}
+	params := make(map[string]interface{})
+	for k, v := range cmd.Fixed {
+		params[k] = v
+	}
+	for k := range values {
+		if !slices.ContainsFunc(cmd.Params, func(p ParamSchema) bool { return p.Name == k }) {
+			return nil, fmt.Errorf("unknown parameter %s of command %s", k, name)
+		}
+	}
+	for _, p := range cmd.Params {
+		v, ok := values[p.Name]
+		if !ok {
+			if p.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("parameter %s of command %s is required", p.Name, name)
+		}
+		value, err := p.value(v)
+		if err != nil {
+			return nil, err
+		}
+		params[p.Name] = value
+	}
+
+	command := Command{
+		Sn:          d.sn,
+		OperateType: cmd.OperateType,
+		ModuleType:  ModuleType(cmd.ModuleType),
+		CmdCode:     cmd.CmdCode,
+		Params:      params,
+	}
+	switch {
+	case cmd.CmdFunc != nil:
+		command.CmdFunc = *cmd.CmdFunc
+		if cmd.CmdId != nil {
+			command.CmdId = *cmd.CmdId
+		}
+	case cmd.CmdSet != nil:
+		params["cmdSet"] = *cmd.CmdSet
+		params["id"] = *cmd.Id
+	}
+	return command.Request()
+}
+
+// SendCommand validates the values, builds the command and sends it to the device
+func (d *GenericDevice) SendCommand(ctx context.Context, name string, values map[string]interface{}) (*CmdSetResponse, error) {
+	req, err := d.BuildCommand(name, values)
+	if err != nil {
+		return nil, err
+	}
+	return d.c.SetDeviceParameter(ctx, req)
+}
+
+// GetStatus returns the telemetry fields of the schema, see DeviceSchema.Decode
+func (d *GenericDevice) GetStatus(ctx context.Context) (map[string]interface{}, error) {
+	params, err := d.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return d.schema.Decode(params), nil
+}
+
+func (d *GenericDevice) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
+	return d.c.GetDeviceParameters(ctx, d.sn, params)
+}
+
+func (d *GenericDevice) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
+	return d.c.GetDeviceAllParameters(ctx, d.sn)
+}
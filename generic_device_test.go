@@ -0,0 +1,141 @@
+package ecoflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDeviceSchema = `{
+	"name": "Test device",
+	"commands": [
+		{"name": "ecoMode", "operateType": "ecoMode", "moduleType": 1, "params": [{"name": "ecoMode", "type": "int", "enum": [0, 1]}]},
+		{"name": "acCharging", "cmdCode": "YJ751_PD_AC_CHG_SET", "params": [
+			{"name": "chgC", "type": "int", "min": 1, "max": 30},
+			{"name": "chgPause", "type": "bool", "optional": true}
+		]},
+		{"name": "circuit", "operateType": "TCP", "cmdSet": 11, "id": 16, "params": [{"name": "ch", "type": "int", "min": 0, "max": 9}]},
+		{"name": "beep", "cmdFunc": 254, "params": [{"name": "cfgBeepEn", "type": "bool"}]},
+		{"name": "backup", "cmdSet": 32, "id": 94, "fixed": {"isConfig": 1}, "params": [{"name": "bpPowerSoc", "type": "float", "min": 5, "max": 100}]}
+	],
+	"telemetry": [
+		{"name": "soc", "key": "pd.soc", "type": "int"},
+		{"name": "voltage", "key": "bms.vol", "scale": 0.001, "unit": "V"},
+		{"name": "ac_enabled", "key": "inv.cfgAcEnabled", "type": "bool"},
+		{"name": "model", "key": "pd.model", "type": "string"}
+	]
+}`
+
+func TestGenericDevice(t *testing.T) {
+	schema, err := ParseDeviceSchemaJSON([]byte(testDeviceSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client, last := newTestClient(t, map[string]interface{}{
+		"pd.soc":           81,
+		"bms.vol":          51200,
+		"inv.cfgAcEnabled": 1,
+		"pd.model":         "X1",
+	})
+	d := client.GetGenericDevice("XX11ZZ0000000001", schema)
+	ctx := context.Background()
+
+	status, err := d.GetStatus(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status["soc"] != 81 || status["voltage"] != 51.2 || status["ac_enabled"] != true || status["model"] != "X1" {
+		t.Errorf("unexpected status %v", status)
+	}
+
+	tests := []struct {
+		command string
+		values  map[string]interface{}
+		header  map[string]interface{}
+		params  map[string]interface{}
+	}{
+		{"ecoMode", map[string]interface{}{"ecoMode": SettingEnabled},
+			map[string]interface{}{"operateType": "ecoMode", "moduleType": float64(1)}, map[string]interface{}{"ecoMode": float64(1)}},
+		{"acCharging", map[string]interface{}{"chgC": 10},
+			map[string]interface{}{"cmdCode": "YJ751_PD_AC_CHG_SET"}, map[string]interface{}{"chgC": float64(10)}},
+		{"circuit", map[string]interface{}{"ch": 3},
+			map[string]interface{}{"operateType": "TCP"}, map[string]interface{}{"cmdSet": float64(11), "id": float64(16), "ch": float64(3)}},
+		{"beep", map[string]interface{}{"cfgBeepEn": false},
+			map[string]interface{}{"cmdId": float64(17), "cmdFunc": float64(254), "needAck": true}, map[string]interface{}{"cfgBeepEn": false}},
+		{"backup", map[string]interface{}{"bpPowerSoc": 40},
+			map[string]interface{}{}, map[string]interface{}{"cmdSet": float64(32), "id": float64(94), "isConfig": float64(1), "bpPowerSoc": float64(40)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if _, err := d.SendCommand(ctx, tt.command, tt.values); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for k, v := range tt.header {
+				if (*last)[k] != v {
+					t.Errorf("%s is %v, expected %v", k, (*last)[k], v)
+				}
+			}
+			params := (*last)["params"].(map[string]interface{})
+			if len(params) != len(tt.params) {
+				t.Errorf("unexpected params %v", params)
+			}
+			for k, v := range tt.params {
+				if params[k] != v {
+					t.Errorf("%s is %v, expected %v", k, params[k], v)
+				}
+			}
+		})
+	}
+
+	invalid := []struct {
+		command string
+		values  map[string]interface{}
+	}{
+		{"unknown", nil},
+		{"ecoMode", map[string]interface{}{"ecoMode": 2}},
+		{"ecoMode", map[string]interface{}{}},
+		{"ecoMode", map[string]interface{}{"ecoMode": 1, "extra": 1}},
+		{"acCharging", map[string]interface{}{"chgC": 31}},
+		{"acCharging", map[string]interface{}{"chgC": 1.5}},
+		{"beep", map[string]interface{}{"cfgBeepEn": 1}},
+	}
+	for _, tt := range invalid {
+		if _, err := d.BuildCommand(tt.command, tt.values); err == nil {
+			t.Errorf("expected error for %s %v", tt.command, tt.values)
+		}
+	}
+}
+
+func TestLoadDeviceSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "device.json")
+	data := `{"name": "Test", "commands": [{"name": "beep", "cmdFunc": 254, "params": [{"name": "cfgBeepEn", "type": "bool"}]}],
+		"telemetry": [{"name": "soc", "key": "bmsBattSoc"}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := LoadDeviceSchema(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(schema.Commands) != 1 || len(schema.Telemetry) != 1 {
+		t.Errorf("unexpected schema %+v", schema)
+	}
+
+	invalid := []string{
+		`{"commands": [{"name": "a"}]}`,
+		`{"commands": [{"name": "a", "operateType": "x", "cmdCode": "y"}]}`,
+		`{"commands": [{"name": "a", "cmdSet": 11}]}`,
+		`{"commands": [{"name": "a", "cmdCode": "y", "params": [{"name": "p", "type": "number"}]}]}`,
+		`{"commands": [{"name": "a", "cmdCode": "y"}, {"name": "a", "cmdCode": "z"}]}`,
+		`{"telemetry": [{"name": "soc"}]}`,
+		`{"commands": [{"name": "a", "cmdCode": "y", "moduleType": 1}]}`,
+		`{"commands": [{"name": "a", "cmdFunc": 254, "moduleType": 1}]}`,
+		`{"commands": [{"name": "a", "operateType": "TCP", "cmdSet": 11, "id": 1, "moduleType": 1}]}`,
+	}
+	for _, s := range invalid {
+		if _, err = ParseDeviceSchemaJSON([]byte(s)); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+}
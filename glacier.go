@@ -2,23 +2,27 @@ package ecoflow
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"time"
+	"errors"
 )
 
 // Ecoflow documentation:
 // https://developer-eu.ecoflow.com/us/document/glacier
 
 type Glacier struct {
-	c  *Client
-	sn string
+	c    *Client
+	sn   string
+	caps GlacierCapabilities
 }
 
 func (g *Glacier) GetSn() string {
 	return g.sn
 }
 
+// Capabilities returns the zones, the ice maker and the temperature ranges of the model, the setters reject the unsupported commands
+func (g *Glacier) Capabilities() GlacierCapabilities {
+	return g.caps
+}
+
 type GlacierModeType int
 
 const (
@@ -57,11 +61,147 @@ const (
 	GlacierVoltageProtectionLevelHigh   GlacierVoltageProtectionLevel = 2
 )
 
+// GlacierIceMakingState is the state of the ice maker (pd.fsmState)
+type GlacierIceMakingState int
+
+const (
+	GlacierIceMakingStateDetaching         GlacierIceMakingState = 4
+	GlacierIceMakingStateDetachingComplete GlacierIceMakingState = 5
+)
+
+// GlacierStatus is the state of a Glacier fridge decoded from "pd.*" and "bms_bmsStatus.*" parameters.
+// The temperatures are in Unit
+type GlacierStatus struct {
+	Unit TemperatureUnit
+	// DualZone is false if the middle partition is removed and the fridge works as a single zone (tmpM)
+	DualZone bool
+	// LeftTemperature, RightTemperature and CombinedTemperature are the measured temperatures of the zones
+	LeftTemperature     float64
+	RightTemperature    float64
+	CombinedTemperature float64
+	// LeftTarget, RightTarget and CombinedTarget are the temperature settings of the zones
+	LeftTarget     int
+	RightTarget    int
+	CombinedTarget int
+	EcoMode        bool
+	DoorOpen       bool
+	IceMaking      GlacierIceMakingState
+	IceShape       GlacierIceShape
+	// IcePercent is the progress of the ice making, %
+	IcePercent int
+	// BatteryPresent is true if the battery is installed
+	BatteryPresent bool
+	// BatterySoc is the battery level, %
+	BatterySoc float64
+	// CompressorWatts is the power of the compressor, W
+	CompressorWatts float64
+	// CompressorSpeed is the speed of the compressor, rpm
+	CompressorSpeed int
+	// AmbientTemperature is the temperature around the fridge
+	AmbientTemperature float64
+}
+
+// GetStatus returns the typed state of the fridge
+func (g *Glacier) GetStatus(ctx context.Context) (*GlacierStatus, error) {
+	params, err := g.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeGlacierStatus(params)
+}
+
+// DecodeGlacierStatus decodes the fridge state from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// Missing parameters have zero values, an error is returned only if the temperature settings are not found
+func DecodeGlacierStatus(params map[string]interface{}) (*GlacierStatus, error) {
+	if _, ok := NumericValue(params["pd.tmpLSet"]); !ok {
+		return nil, errors.New("pd.tmpLSet parameter is not found")
+	}
+	value := func(key string) float64 {
+		v, _ := NumericValue(params[key])
+		return v
+	}
+	return &GlacierStatus{
+		Unit:                TemperatureUnit(value("pd.tmpUnit")),
+		DualZone:            value("pd.flagTwoZone") != 0,
+		LeftTemperature:     value("pd.tmpL"),
+		RightTemperature:    value("pd.tmpR"),
+		CombinedTemperature: value("pd.tmpM"),
+		LeftTarget:          int(value("pd.tmpLSet")),
+		RightTarget:         int(value("pd.tmpRSet")),
+		CombinedTarget:      int(value("pd.tmpMSet")),
+		EcoMode:             value("pd.coolMode") == 1,
+		DoorOpen:            value("pd.doorStat") != 0,
+		IceMaking:           GlacierIceMakingState(value("pd.fsmState")),
+		IceShape:            GlacierIceShape(value("pd.iceMkMode")),
+		IcePercent:          int(value("pd.icePercent")),
+		BatteryPresent:      value("pd.batFlag") != 0,
+		BatterySoc:          value("bms_bmsStatus.soc"),
+		CompressorWatts:     value("pd.motorWat"),
+		CompressorSpeed:     int(value("pd.motorSpeed")),
+		AmbientTemperature:  value("pd.ambientTmp"),
+	}, nil
+}
+
+// SetZoneTemperatures sets the temperatures of the left and the right zones in the dual-zone mode.
+// The temperatures are in the unit set by SetTemperatureUnit (range of Glacier: -25:10℃ or -13:50℉),
+// the difference between the zones cannot exceed 25℃ (45℉). The unit, the partition and the combined target are read from the device
+func (g *Glacier) SetZoneTemperatures(ctx context.Context, left, right int) (*CmdSetResponse, error) {
+	if err := g.caps.validateDualZone(); err != nil {
+		return nil, err
+	}
+	status, err := g.currentStatus(ctx, "pd.tmpMSet")
+	if err != nil {
+		return nil, err
+	}
+	if !status.DualZone {
+		return nil, errors.New("the partition is removed, use SetCombinedTemperature")
+	}
+	if err = g.caps.validateTemperatures(status.Unit, &[2]int{right, left}); err != nil {
+		return nil, err
+	}
+	return g.setTemperature(ctx, right, left, status.CombinedTarget)
+}
+
+// SetCombinedTemperature sets the temperature in the single-zone mode (the middle partition is removed).
+// The temperature is in the unit set by SetTemperatureUnit (range of Glacier: -25:10℃ or -13:50℉).
+// The unit, the partition and the zone targets are read from the device
+func (g *Glacier) SetCombinedTemperature(ctx context.Context, temperature int) (*CmdSetResponse, error) {
+	status, err := g.currentStatus(ctx, "pd.tmpRSet", "pd.tmpLSet")
+	if err != nil {
+		return nil, err
+	}
+	if status.DualZone {
+		return nil, errors.New("the partition is installed, use SetZoneTemperatures")
+	}
+	if err = g.caps.validateTemperatures(status.Unit, nil, temperature); err != nil {
+		return nil, err
+	}
+	return g.setTemperature(ctx, status.RightTarget, status.LeftTarget, temperature)
+}
+
+// currentStatus reads the state for the temperature setters: the unit, the partition and the targets that are sent
+// unchanged (keys) must be reported
+func (g *Glacier) currentStatus(ctx context.Context, keys ...string) (*GlacierStatus, error) {
+	params, err := g.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = requireParameters(params, append([]string{"pd.tmpUnit", "pd.flagTwoZone"}, keys...)...); err != nil {
+		return nil, err
+	}
+	return DecodeGlacierStatus(params)
+}
+
 // SetTemperature Set temperature(tmpR indicates the temperature of the right side of the refrigerator,
 // tmpL indicates the temperature of the left side, and tmpM indicates the temperature setting after the middle partition is removed.
-// The difference between tmpR and tmpL cannot exceed 25℃)
+// The difference between tmpR and tmpL cannot exceed 25℃). The temperatures are sent as is, the device state isn't read:
+// SetZoneTemperatures and SetCombinedTemperature validate them against the unit and the partition of the device
 // { "id":123456789, "version":"1.0", "sn":"BX11ZCB4EF2E0002", "moduleType":1, "operateType":"temp", "params":{ "tmpR":-19, "tmpL":0, "tmpM":0 } }
 func (g *Glacier) SetTemperature(ctx context.Context, tmpR, tmpL, tmpM int) (*CmdSetResponse, error) {
+	return g.setTemperature(ctx, tmpR, tmpL, tmpM)
+}
+
+func (g *Glacier) setTemperature(ctx context.Context, tmpR, tmpL, tmpM int) (*CmdSetResponse, error) {
 	params := make(map[string]interface{})
 	params["tmpR"] = tmpR
 	params["tmpL"] = tmpL
@@ -104,6 +244,9 @@ func (g *Glacier) SetScreenTimeout(ctx context.Context, time int) (*CmdSetRespon
 // SetTemperatureUnit Set temperature unit(0: Celsius; 1: Fahrenheit）
 // { "id":123456789, "version":"1.0", "sn":"BX11ZCB4EF2E0002", "moduleType":1, "operateType":"tmpUnit", "params":{ "unit":0 } }
 func (g *Glacier) SetTemperatureUnit(ctx context.Context, unit TemperatureUnit) (*CmdSetResponse, error) {
+	if unit != TemperatureUnitCelsius && unit != TemperatureUnitFahrenheit {
+		return nil, errors.New("unit is out of range. Range 0:1")
+	}
 	params := make(map[string]interface{})
 	params["unit"] = unit
 	return g.setParameter(ctx, "tmpUnit", params)
@@ -113,6 +256,9 @@ func (g *Glacier) SetTemperatureUnit(ctx context.Context, unit TemperatureUnit)
 // If "enable"=1 and "iceShape"=0, the device will make small ice cubes. If "enable"=1 and "iceShape"=1, the device will make large ice cubes.)
 // { "id":123456789, "version":"1.0", "sn":"BX11ZCB4EF2E0002", "moduleType":1, "operateType":"iceMake", "params":{ "enable":1, "iceShape":1 } }
 func (g *Glacier) SetIceMaking(ctx context.Context, enable SettingSwitcher, iceShape GlacierIceShape) (*CmdSetResponse, error) {
+	if err := g.caps.validateIceMaker(); err != nil {
+		return nil, err
+	}
 	params := make(map[string]interface{})
 	params["enable"] = enable
 	params["iceShape"] = iceShape
@@ -122,6 +268,9 @@ func (g *Glacier) SetIceMaking(ctx context.Context, enable SettingSwitcher, iceS
 // SetIceDetaching Set ice detaching(enable: 0: Invalid, 1: Detach iceiceTm: Duration of ice detaching; unit: secfsmState: 4: Detaching ice, 5: Detaching completed）
 // { "id":123456789, "version":"1.0", "sn":"BX11ZCB4EF2E0002", "moduleType":1, "operateType":"deIce", "params":{ "enable":0 } }
 func (g *Glacier) SetIceDetaching(ctx context.Context, enable SettingSwitcher) (*CmdSetResponse, error) {
+	if err := g.caps.validateIceMaker(); err != nil {
+		return nil, err
+	}
 	params := make(map[string]interface{})
 	params["enable"] = enable
 	return g.setParameter(ctx, "deIce", params)
@@ -156,24 +305,10 @@ func (g *Glacier) GetAllParameters(ctx context.Context) (map[string]interface{},
 
 // internal function to generate a request for setting the parameters
 func (g *Glacier) setParameter(ctx context.Context, opType string, params map[string]interface{}) (*CmdSetResponse, error) {
-	cmdReq := CmdSetRequest{
-		Id:          fmt.Sprint(time.Now().UnixMilli()),
+	return g.c.SendCommand(ctx, Command{
+		Sn:          g.sn,
 		OperateType: opType,
 		ModuleType:  ModuleTypePd,
-		Sn:          g.sn,
 		Params:      params,
-	}
-
-	jsonData, err := json.Marshal(cmdReq)
-	if err != nil {
-		return nil, err
-	}
-
-	var req map[string]interface{}
-
-	err = json.Unmarshal(jsonData, &req)
-	if err != nil {
-		return nil, err
-	}
-	return g.c.SetDeviceParameter(ctx, req)
+	})
 }
@@ -0,0 +1,164 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func glacierQuota() map[string]interface{} {
+	return map[string]interface{}{
+		"pd.tmpUnit":        0,
+		"pd.flagTwoZone":    1,
+		"pd.tmpL":           -3,
+		"pd.tmpR":           -18,
+		"pd.tmpLSet":        -2,
+		"pd.tmpRSet":        -19,
+		"pd.tmpMSet":        0,
+		"pd.doorStat":       1,
+		"pd.fsmState":       4,
+		"bms_bmsStatus.soc": 87,
+	}
+}
+
+func TestGlacierStatus(t *testing.T) {
+	client, _ := newTestClient(t, glacierQuota())
+	status, err := client.GetGlacier("BX11ZCB4EF2E0002").GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := GlacierStatus{DualZone: true, LeftTemperature: -3, RightTemperature: -18, LeftTarget: -2, RightTarget: -19,
+		DoorOpen: true, IceMaking: GlacierIceMakingStateDetaching, BatterySoc: 87}
+	if *status != expected {
+		t.Errorf("got %+v, expected %+v", *status, expected)
+	}
+}
+
+func TestGlacierTemperatures(t *testing.T) {
+	dualZone := glacierQuota()
+	singleZone := glacierQuota()
+	singleZone["pd.tmpUnit"] = 1
+	singleZone["pd.flagTwoZone"] = 0
+	missingTarget := glacierQuota()
+	delete(missingTarget, "pd.tmpMSet")
+	missingUnit := glacierQuota()
+	missingUnit["pd.flagTwoZone"] = 0
+	delete(missingUnit, "pd.tmpUnit")
+
+	tests := []struct {
+		name  string
+		quota map[string]interface{}
+		call  func(ctx context.Context, g *Glacier) (*CmdSetResponse, error)
+		// params are the expected parameters, nil if the call must fail
+		params map[string]interface{}
+	}{
+		{
+			name:  "Zone temperatures keep the combined target",
+			quota: dualZone,
+			call: func(ctx context.Context, g *Glacier) (*CmdSetResponse, error) {
+				return g.SetZoneTemperatures(ctx, 5, -20)
+			},
+			params: map[string]interface{}{"tmpL": float64(5), "tmpR": float64(-20), "tmpM": float64(0)},
+		},
+		{
+			name:  "Zones difference above 25℃",
+			quota: dualZone,
+			call: func(ctx context.Context, g *Glacier) (*CmdSetResponse, error) {
+				return g.SetZoneTemperatures(ctx, 6, -20)
+			},
+		},
+		{
+			name:  "Combined temperature in the dual-zone mode",
+			quota: dualZone,
+			call: func(ctx context.Context, g *Glacier) (*CmdSetResponse, error) {
+				return g.SetCombinedTemperature(ctx, 4)
+			},
+		},
+		{
+			name:  "Raw temperatures",
+			quota: dualZone,
+			call: func(ctx context.Context, g *Glacier) (*CmdSetResponse, error) {
+				return g.SetTemperature(ctx, -19, 0, 2)
+			},
+			params: map[string]interface{}{"tmpR": float64(-19), "tmpL": float64(0), "tmpM": float64(2)},
+		},
+		{
+			name:  "Combined temperature in ℉ keeps the zone targets",
+			quota: singleZone,
+			call: func(ctx context.Context, g *Glacier) (*CmdSetResponse, error) {
+				return g.SetCombinedTemperature(ctx, 40)
+			},
+			params: map[string]interface{}{"tmpR": float64(-19), "tmpL": float64(-2), "tmpM": float64(40)},
+		},
+		{
+			name:  "Zone temperatures without the combined target",
+			quota: missingTarget,
+			call: func(ctx context.Context, g *Glacier) (*CmdSetResponse, error) {
+				return g.SetZoneTemperatures(ctx, 5, -20)
+			},
+		},
+		{
+			name:  "Combined temperature without the unit",
+			quota: missingUnit,
+			call: func(ctx context.Context, g *Glacier) (*CmdSetResponse, error) {
+				return g.SetCombinedTemperature(ctx, 4)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		client, last := newTestClient(t, tt.quota)
+		g := client.GetGlacier("BX11ZCB4EF2E0002")
+		runSetterTests(t, last, []setterTest{{
+			name:        tt.name,
+			call:        func(ctx context.Context) (*CmdSetResponse, error) { return tt.call(ctx, g) },
+			operateType: "temp",
+			params:      tt.params,
+		}})
+	}
+}
+
+func TestGlacierModels(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{
+		"pd.tmpUnit":     0,
+		"pd.flagTwoZone": 0,
+		"pd.tmpLSet":     0,
+		"pd.tmpRSet":     0,
+		"pd.tmpMSet":     4,
+	})
+	if _, err := client.GetGlacierModel("BX11ZCB4EF2E0002", "Glacier Mini"); err == nil {
+		t.Errorf("expected error for unknown model")
+	}
+	classic, err := client.GetGlacierModel("BX31ZCB4EF2E0002", GlacierModelGlacierClassic35)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	glacier := client.GetGlacier("BX11ZCB4EF2E0002")
+
+	runSetterTests(t, last, []setterTest{
+		{
+			name: "Zones of a single-zone model",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return classic.SetZoneTemperatures(ctx, 4, -18) },
+		},
+		{
+			name: "Ice making without ice maker",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return classic.SetIceMaking(ctx, SettingEnabled, GlacierIceShapeSmall)
+			},
+		},
+		{
+			name: "Temperature out of the Glacier Classic range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return classic.SetCombinedTemperature(ctx, 21) },
+		},
+		{
+			// 15℃ is out of the Glacier range, but it's supported by Glacier Classic
+			name: "Temperature out of the Glacier range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return glacier.SetCombinedTemperature(ctx, 15) },
+		},
+		{
+			name:        "Glacier Classic temperature",
+			call:        func(ctx context.Context) (*CmdSetResponse, error) { return classic.SetCombinedTemperature(ctx, 15) },
+			operateType: "temp",
+			params:      map[string]interface{}{"tmpR": float64(0), "tmpL": float64(0), "tmpM": float64(15)},
+		},
+	})
+}
@@ -0,0 +1,125 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+const sn = "R331ZEB4ZEAL0528"
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "history.db"), Options{RawRetention: time.Hour, Resolution: time.Minute})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func record(t *testing.T, store *Store, ts time.Time, soc float64) {
+	t.Helper()
+	err := store.Record(context.Background(), ecoflow.DeviceSnapshot{
+		Sn:        sn,
+		Timestamp: ts,
+		Params:    map[string]interface{}{"pd.soc": soc, "pd.model": "Delta 2"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRecordAndQuery(t *testing.T) {
+	ctx := context.Background()
+	store := openStore(t)
+	start := time.UnixMilli(1700000000000).Truncate(time.Minute)
+	for i := 0; i < 4; i++ {
+		record(t, store, start.Add(time.Duration(i)*30*time.Second), float64(50+i))
+	}
+
+	keys, err := store.Keys(ctx, sn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "pd.soc" {
+		t.Errorf("expected only numeric keys, got %v", keys)
+	}
+
+	points, err := store.Range(ctx, sn, "pd.soc", start, start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) != 2 || points[0].Value != 50 || points[1].Value != 51 {
+		t.Errorf("unexpected range %v", points)
+	}
+
+	aggregates, err := store.Aggregate(ctx, sn, "pd.soc", start, start.Add(time.Hour), time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []AggregatePoint{
+		{Time: start, Count: 2, Min: 50, Max: 51, Avg: 50.5, Last: 51},
+		{Time: start.Add(time.Minute), Count: 2, Min: 52, Max: 53, Avg: 52.5, Last: 53},
+	}
+	if len(aggregates) != len(expected) {
+		t.Fatalf("expected %d aggregates, got %v", len(expected), aggregates)
+	}
+	for i := range expected {
+		if !aggregates[i].Time.Equal(expected[i].Time) || aggregates[i].Count != expected[i].Count ||
+			aggregates[i].Min != expected[i].Min || aggregates[i].Max != expected[i].Max ||
+			aggregates[i].Avg != expected[i].Avg || aggregates[i].Last != expected[i].Last {
+			t.Errorf("aggregate %d: got %+v, expected %+v", i, aggregates[i], expected[i])
+		}
+	}
+
+	last, err := store.Last(ctx, sn, "pd.soc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last.Value != 53 {
+		t.Errorf("expected last value 53, got %v", last.Value)
+	}
+
+	if _, err = store.Last(ctx, sn, "pd.unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	ctx := context.Background()
+	store := openStore(t)
+	start := time.UnixMilli(1700000000000).Truncate(time.Minute)
+	for i := 0; i < 4; i++ {
+		record(t, store, start.Add(time.Duration(i)*30*time.Second), float64(50+i))
+	}
+
+	// everything except the last bucket is older than the raw retention
+	if err := store.Downsample(ctx, start.Add(time.Hour+time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// downsampling again must not aggregate the data twice
+	if err := store.Downsample(ctx, start.Add(time.Hour+time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	points, err := store.Range(ctx, sn, "pd.soc", start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(points) != 3 || points[0].Value != 50.5 || points[1].Value != 52 || points[2].Value != 53 {
+		t.Errorf("unexpected range %v", points)
+	}
+
+	aggregates, err := store.Aggregate(ctx, sn, "pd.soc", start, start.Add(time.Hour), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(aggregates) != 1 || aggregates[0].Count != 4 || aggregates[0].Avg != 51.5 || aggregates[0].Last != 53 {
+		t.Errorf("unexpected aggregates %+v", aggregates)
+	}
+}
@@ -0,0 +1,161 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when there is no data for the device parameter
+var ErrNotFound = errors.New("no data found")
+
+// Point is a value of a device parameter at some point of time.
+// For downsampled data Time is the bucket start and Value is the bucket average
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// AggregatePoint is the aggregation of a device parameter over [Time, Time + step)
+type AggregatePoint struct {
+	Time  time.Time
+	Count int64
+	Min   float64
+	Max   float64
+	Avg   float64
+	Last  float64
+}
+
+// Devices returns serial numbers of all devices that have data
+func (s *Store) Devices(ctx context.Context) ([]string, error) {
+	return s.queryStrings(ctx, `SELECT DISTINCT sn FROM series ORDER BY sn`)
+}
+
+// Keys returns names of all device parameters that have data
+func (s *Store) Keys(ctx context.Context, sn string) ([]string, error) {
+	return s.queryStrings(ctx, `SELECT key FROM series WHERE sn = ? ORDER BY key`, sn)
+}
+
+// Range returns the values of the device parameter in [from, to) ordered by time.
+// Raw samples are returned as is, downsampled data is returned as one point per bucket
+func (s *Store) Range(ctx context.Context, sn, key string, from, to time.Time) ([]Point, error) {
+	id, err := s.lookupSeries(ctx, sn, key)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ts, value FROM (
+			SELECT ts, value FROM samples WHERE series_id = ?1 AND ts >= ?2 AND ts < ?3
+			UNION ALL
+			SELECT bucket, value_sum / value_count FROM rollups WHERE series_id = ?1 AND bucket >= ?2 AND bucket < ?3
+		) ORDER BY ts`, id, from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []Point
+	for rows.Next() {
+		var ts int64
+		var p Point
+		if err = rows.Scan(&ts, &p.Value); err != nil {
+			return nil, err
+		}
+		p.Time = time.UnixMilli(ts)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// Aggregate returns the aggregated values of the device parameter in [from, to) split into step intervals starting at from.
+// Intervals without data are not returned
+func (s *Store) Aggregate(ctx context.Context, sn, key string, from, to time.Time, step time.Duration) ([]AggregatePoint, error) {
+	if step < time.Millisecond {
+		return nil, errors.New("step must be at least 1 millisecond")
+	}
+	id, err := s.lookupSeries(ctx, sn, key)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		WITH data AS (
+			SELECT ts, 1 AS cnt, value AS total, value AS mn, value AS mx, value AS last
+			FROM samples WHERE series_id = ?1 AND ts >= ?2 AND ts < ?3
+			UNION ALL
+			SELECT bucket, value_count, value_sum, value_min, value_max, value_last
+			FROM rollups WHERE series_id = ?1 AND bucket >= ?2 AND bucket < ?3
+		)
+		SELECT ?2 + ((ts - ?2) / ?4) * ?4 AS start, sum(cnt), min(mn), max(mx), sum(total) / sum(cnt), max(lastv) FROM (
+			SELECT ts, cnt, total, mn, mx, last_value(last) OVER (
+				PARTITION BY (ts - ?2) / ?4 ORDER BY ts ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING
+			) AS lastv FROM data
+		)
+		GROUP BY start ORDER BY start`, id, from.UnixMilli(), to.UnixMilli(), step.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []AggregatePoint
+	for rows.Next() {
+		var ts int64
+		var p AggregatePoint
+		if err = rows.Scan(&ts, &p.Count, &p.Min, &p.Max, &p.Avg, &p.Last); err != nil {
+			return nil, err
+		}
+		p.Time = time.UnixMilli(ts)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// Last returns the most recent value of the device parameter
+func (s *Store) Last(ctx context.Context, sn, key string) (*Point, error) {
+	id, err := s.lookupSeries(ctx, sn, key)
+	if err != nil {
+		return nil, err
+	}
+	var ts int64
+	var p Point
+	err = s.db.QueryRowContext(ctx, `
+		SELECT ts, value FROM (
+			SELECT ts, value FROM samples WHERE series_id = ?1
+			UNION ALL
+			SELECT bucket, value_last FROM rollups WHERE series_id = ?1
+		) ORDER BY ts DESC LIMIT 1`, id).Scan(&ts, &p.Value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.Time = time.UnixMilli(ts)
+	return &p, nil
+}
+
+func (s *Store) lookupSeries(ctx context.Context, sn, key string) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM series WHERE sn = ? AND key = ?`, sn, key).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return id, err
+}
+
+func (s *Store) queryStrings(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var result []string
+	for rows.Next() {
+		var v string
+		if err = rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		result = append(result, v)
+	}
+	return result, rows.Err()
+}
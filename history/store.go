@@ -0,0 +1,240 @@
+// Package history stores device parameters in an embedded SQLite database and provides queries over the stored data.
+//
+// Every numeric parameter of a device is a separate time series. The received values are appended to the raw samples
+// table. Raw samples older than RawRetention are downsampled to Resolution buckets (count, sum, min, max, last) and
+// removed, the buckets older than RollupRetention are removed as well (see Store.Downsample).
+package history
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	defaultRawRetention    = 7 * 24 * time.Hour
+	defaultResolution      = 5 * time.Minute
+	defaultRollupRetention = 365 * 24 * time.Hour
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS series (
+	id  INTEGER PRIMARY KEY,
+	sn  TEXT NOT NULL,
+	key TEXT NOT NULL,
+	UNIQUE (sn, key)
+);
+CREATE TABLE IF NOT EXISTS samples (
+	series_id INTEGER NOT NULL REFERENCES series (id),
+	ts        INTEGER NOT NULL,
+	value     REAL    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS samples_series_ts ON samples (series_id, ts);
+CREATE TABLE IF NOT EXISTS rollups (
+	series_id   INTEGER NOT NULL REFERENCES series (id),
+	bucket      INTEGER NOT NULL,
+	value_count INTEGER NOT NULL,
+	value_sum   REAL    NOT NULL,
+	value_min   REAL    NOT NULL,
+	value_max   REAL    NOT NULL,
+	value_last  REAL    NOT NULL,
+	PRIMARY KEY (series_id, bucket)
+);
+`
+
+type Options struct {
+	// RawRetention is how long the raw samples are kept before they are downsampled, 7 days by default
+	RawRetention time.Duration
+	// Resolution is the bucket size of the downsampled data, 5 minutes by default.
+	// It must not be changed for an existing database
+	Resolution time.Duration
+	// RollupRetention is how long the downsampled data is kept, 365 days by default
+	RollupRetention time.Duration
+	// Filter selects the parameters to store, all numeric parameters are stored if nil
+	Filter *ecoflow.KeyFilter
+}
+
+// Store is the history database. It's safe for concurrent use
+type Store struct {
+	db      *sql.DB
+	options Options
+	mu      sync.Mutex
+	series  map[seriesKey]int64
+}
+
+type seriesKey struct {
+	sn  string
+	key string
+}
+
+// Open opens (or creates) the database file
+func Open(path string, options Options) (*Store, error) {
+	if options.RawRetention <= 0 {
+		options.RawRetention = defaultRawRetention
+	}
+	if options.Resolution <= 0 {
+		options.Resolution = defaultResolution
+	}
+	if options.RollupRetention <= 0 {
+		options.RollupRetention = defaultRollupRetention
+	}
+	if options.RollupRetention < options.RawRetention {
+		return nil, errors.New("rollup retention must not be less than raw retention")
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// sqlite doesn't support concurrent writes
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Store{db: db, options: options, series: make(map[seriesKey]int64)}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Record appends all numeric parameters of the snapshot to the raw samples
+func (s *Store) Record(ctx context.Context, snapshot ecoflow.DeviceSnapshot) error {
+	ts := snapshot.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	created := make(map[seriesKey]int64)
+	for k, v := range snapshot.Params {
+		if !s.options.Filter.Match(k) {
+			continue
+		}
+		value, ok := ecoflow.NumericValue(v)
+		if !ok {
+			continue
+		}
+		id, err := s.seriesId(ctx, tx, seriesKey{sn: snapshot.Sn, key: k}, created)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, `INSERT INTO samples (series_id, ts, value) VALUES (?, ?, ?)`, id, ts.UnixMilli(), value); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	// the new series are cached only when the transaction is committed
+	for sk, id := range created {
+		s.series[sk] = id
+	}
+	return nil
+}
+
+// Write records the snapshot and logs the error if any.
+// It can be used as ecoflow.PollerConfig.OnSnapshot handler or with ecoflow.MqttClient.SubscribeForSnapshots
+func (s *Store) Write(snapshot ecoflow.DeviceSnapshot) {
+	if err := s.Record(context.Background(), snapshot); err != nil {
+		slog.Error("Unable to record snapshot", "sn", snapshot.Sn, "error", err)
+	}
+}
+
+// seriesId returns the id of the time series, the series is created if it doesn't exist and added to created map
+func (s *Store) seriesId(ctx context.Context, tx *sql.Tx, sk seriesKey, created map[seriesKey]int64) (int64, error) {
+	if id, ok := s.series[sk]; ok {
+		return id, nil
+	}
+	if id, ok := created[sk]; ok {
+		return id, nil
+	}
+	_, err := tx.ExecContext(ctx, `INSERT INTO series (sn, key) VALUES (?, ?) ON CONFLICT (sn, key) DO NOTHING`, sk.sn, sk.key)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	if err = tx.QueryRowContext(ctx, `SELECT id FROM series WHERE sn = ? AND key = ?`, sk.sn, sk.key).Scan(&id); err != nil {
+		return 0, err
+	}
+	created[sk] = id
+	return id, nil
+}
+
+// Downsample aggregates the raw samples older than RawRetention (relative to now) to Resolution buckets,
+// removes the aggregated raw samples and removes the buckets older than RollupRetention.
+// Only complete buckets are aggregated, so the data is never aggregated twice
+func (s *Store) Downsample(ctx context.Context, now time.Time) error {
+	resolution := s.options.Resolution.Milliseconds()
+	cutoff := now.Add(-s.options.RawRetention).UnixMilli()
+	cutoff -= cutoff % resolution
+	rollupCutoff := now.Add(-s.options.RollupRetention).UnixMilli()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// the last value of a bucket is the value of the sample with the maximum timestamp, it's the same for all rows of the bucket
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rollups (series_id, bucket, value_count, value_sum, value_min, value_max, value_last)
+		SELECT series_id, bucket, count(*), sum(value), min(value), max(value), max(last) FROM (
+			SELECT series_id, ts - ts % ?1 AS bucket, value, last_value(value) OVER (
+				PARTITION BY series_id, ts - ts % ?1 ORDER BY ts ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING
+			) AS last
+			FROM samples WHERE ts < ?2
+		)
+		WHERE true
+		GROUP BY series_id, bucket
+		ON CONFLICT (series_id, bucket) DO UPDATE SET
+			value_count = value_count + excluded.value_count,
+			value_sum = value_sum + excluded.value_sum,
+			value_min = min(value_min, excluded.value_min),
+			value_max = max(value_max, excluded.value_max),
+			value_last = excluded.value_last`, resolution, cutoff)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM samples WHERE ts < ?`, cutoff); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM rollups WHERE bucket < ?`, rollupCutoff); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Run executes Downsample every interval until the context is cancelled. Errors are logged
+func (s *Store) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Downsample(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.Error("Unable to downsample history", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
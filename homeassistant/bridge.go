@@ -0,0 +1,369 @@
+// Package homeassistant republishes Ecoflow device parameters to a local MQTT broker using Home Assistant MQTT discovery
+// and maps the commands received from Home Assistant back to the library API.
+//
+// Topics (with default prefixes):
+//
+//	homeassistant/<component>/ecoflow_<sn>/<entity>/config - discovery configuration (retained)
+//	ecoflow/<sn>/availability                               - "online" / "offline" (retained)
+//	ecoflow/<sn>/<entity>/state                             - entity state (retained)
+//	ecoflow/<sn>/<entity>/set                               - commands from Home Assistant
+//	homeassistant/status                                    - Home Assistant birth message, discovery is re-published on "online"
+//
+// The bridge must be notified about the (re)connections to the local broker with OnConnect,
+// the broker forgets the subscriptions of a clean session
+package homeassistant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tess1o/go-ecoflow"
+)
+
+const (
+	defaultDiscoveryPrefix = "homeassistant"
+	defaultTopicPrefix     = "ecoflow"
+	defaultCommandTimeout  = 30 * time.Second
+
+	payloadOn      = "ON"
+	payloadOff     = "OFF"
+	payloadOnline  = "online"
+	payloadOffline = "offline"
+)
+
+type Config struct {
+	// DiscoveryPrefix is the Home Assistant discovery prefix, "homeassistant" by default
+	DiscoveryPrefix string
+	// TopicPrefix is the prefix of state and command topics, "ecoflow" by default
+	TopicPrefix string
+	// CommandTimeout is the timeout of the Ecoflow API call made for a command, 30 seconds by default
+	CommandTimeout time.Duration
+}
+
+// Bridge publishes device states to the local broker and executes commands received from Home Assistant
+type Bridge struct {
+	local   mqtt.Client
+	client  *ecoflow.Client
+	config  Config
+	mu      sync.Mutex
+	devices map[string]*bridgeDevice
+}
+
+type bridgeDevice struct {
+	device   ecoflow.Device
+	entities []Entity
+	state    State
+}
+
+// NewBridge creates a bridge. local must be connected to the local broker, client is used to execute commands
+func NewBridge(local mqtt.Client, client *ecoflow.Client, config Config) *Bridge {
+	if config.DiscoveryPrefix == "" {
+		config.DiscoveryPrefix = defaultDiscoveryPrefix
+	}
+	if config.TopicPrefix == "" {
+		config.TopicPrefix = defaultTopicPrefix
+	}
+	if config.CommandTimeout == 0 {
+		config.CommandTimeout = defaultCommandTimeout
+	}
+	return &Bridge{
+		local:   local,
+		client:  client,
+		config:  config,
+		devices: make(map[string]*bridgeDevice),
+	}
+}
+
+// UpdateDevices adds new devices (publishes discovery configuration and subscribes to command topics)
+// and publishes the availability of all devices. It can be used as ecoflow.PollerConfig.OnDevices handler
+func (b *Bridge) UpdateDevices(devices []ecoflow.Device) {
+	for _, d := range devices {
+		if err := b.AddDevice(d); err != nil {
+			slog.Error("Unable to add device", "sn", d.SN, "error", err)
+			continue
+		}
+		availability := payloadOffline
+		if d.IsOnline() {
+			availability = payloadOnline
+		}
+		b.publish(b.availabilityTopic(d.SN), availability)
+	}
+}
+
+// AddDevice publishes discovery configuration of the device entities and subscribes to their command topics.
+// Adding the same device again does nothing
+func (b *Bridge) AddDevice(d ecoflow.Device) error {
+	b.mu.Lock()
+	if _, ok := b.devices[d.SN]; ok {
+		b.mu.Unlock()
+		return nil
+	}
+	bd := &bridgeDevice{device: d, entities: Entities(b.client, d), state: make(State)}
+	b.devices[d.SN] = bd
+	b.mu.Unlock()
+
+	if err := b.publishDiscovery(bd); err != nil {
+		return err
+	}
+	return b.subscribeCommands(bd)
+}
+
+// OnConnect subscribes to the Home Assistant status topic and to the command topics of the known devices and publishes
+// their discovery configuration again. It must be set as the handler of the local broker connection:
+//
+//	opts.SetOnConnectHandler(func(c mqtt.Client) { bridge.OnConnect(c) })
+func (b *Bridge) OnConnect(_ mqtt.Client) {
+	token := b.local.Subscribe(b.statusTopic(), 1, func(_ mqtt.Client, msg mqtt.Message) {
+		if string(msg.Payload()) == payloadOnline {
+			// the handler mustn't block the client, the messages are published by another goroutine
+			go b.announceAll(false)
+		}
+	})
+	if token.Wait() && token.Error() != nil {
+		slog.Error("Unable to subscribe to Home Assistant status", "topic", b.statusTopic(), "error", token.Error())
+	}
+	b.announceAll(true)
+}
+
+// announceAll publishes discovery configuration of the known devices, and subscribes to their command topics if subscribe is true
+func (b *Bridge) announceAll(subscribe bool) {
+	b.mu.Lock()
+	devices := make([]*bridgeDevice, 0, len(b.devices))
+	for _, bd := range b.devices {
+		devices = append(devices, bd)
+	}
+	b.mu.Unlock()
+
+	for _, bd := range devices {
+		err := b.publishDiscovery(bd)
+		if err == nil && subscribe {
+			err = b.subscribeCommands(bd)
+		}
+		if err != nil {
+			slog.Error("Unable to announce device", "sn", bd.device.SN, "error", err)
+		}
+	}
+}
+
+func (b *Bridge) publishDiscovery(bd *bridgeDevice) error {
+	for _, e := range bd.entities {
+		config, err := json.Marshal(b.discoveryConfig(bd.device, e))
+		if err != nil {
+			return err
+		}
+		b.publish(b.discoveryTopic(bd.device.SN, e), string(config))
+	}
+	return nil
+}
+
+func (b *Bridge) subscribeCommands(bd *bridgeDevice) error {
+	sn := bd.device.SN
+	for _, e := range bd.entities {
+		if e.Command == nil {
+			continue
+		}
+		entity := e
+		token := b.local.Subscribe(b.commandTopic(sn, e), 1, func(_ mqtt.Client, msg mqtt.Message) {
+			b.handleCommand(sn, entity, string(msg.Payload()))
+		})
+		if token.Wait() && token.Error() != nil {
+			return token.Error()
+		}
+	}
+	return nil
+}
+
+// Update merges the snapshot into the known device state and publishes the states of the device entities.
+// It can be used as ecoflow.PollerConfig.OnSnapshot handler or with ecoflow.MqttClient.SubscribeForSnapshots
+func (b *Bridge) Update(s ecoflow.DeviceSnapshot) {
+	b.mu.Lock()
+	bd, ok := b.devices[s.Sn]
+	b.mu.Unlock()
+	if !ok {
+		if err := b.AddDevice(ecoflow.Device{DeviceInfo: ecoflow.DeviceInfo{SN: s.Sn, Online: 1}, Type: s.DeviceType}); err != nil {
+			slog.Error("Unable to add device", "sn", s.Sn, "error", err)
+			return
+		}
+		b.publish(b.availabilityTopic(s.Sn), payloadOnline)
+		b.mu.Lock()
+		bd = b.devices[s.Sn]
+		b.mu.Unlock()
+	}
+
+	b.mu.Lock()
+	for k, v := range s.Params {
+		bd.state[k] = v
+	}
+	b.mu.Unlock()
+
+	for _, e := range bd.entities {
+		if _, ok := s.Params[e.StateKey]; !ok {
+			continue
+		}
+		if payload, ok := statePayload(e, s.Params[e.StateKey]); ok {
+			b.publish(b.stateTopic(s.Sn, e), payload)
+		}
+	}
+}
+
+func (b *Bridge) handleCommand(sn string, e Entity, payload string) {
+	value, err := commandValue(e, payload)
+	if err != nil {
+		slog.Error("Invalid command", "sn", sn, "entity", e.Key, "payload", payload, "error", err)
+		return
+	}
+
+	b.mu.Lock()
+	state := make(State, len(b.devices[sn].state))
+	for k, v := range b.devices[sn].state {
+		state[k] = v
+	}
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.config.CommandTimeout)
+	defer cancel()
+	if err = e.Command(ctx, state, value); err != nil {
+		slog.Error("Unable to execute command", "sn", sn, "entity", e.Key, "payload", payload, "error", err)
+		return
+	}
+	slog.Debug("Command executed", "sn", sn, "entity", e.Key, "payload", payload)
+}
+
+// statePayload converts the raw parameter value to the Home Assistant entity state
+func statePayload(e Entity, raw interface{}) (string, bool) {
+	v, ok := ecoflow.NumericValue(raw)
+	if !ok {
+		return "", false
+	}
+	switch e.Component {
+	case ComponentSwitch:
+		if v != 0 {
+			return payloadOn, true
+		}
+		return payloadOff, true
+	case ComponentSelect:
+		return e.optionLabel(int(v))
+	default:
+		return strconv.FormatFloat(v*e.scale(), 'f', -1, 64), true
+	}
+}
+
+// commandValue converts the payload received from Home Assistant to the raw value passed to the entity command
+func commandValue(e Entity, payload string) (float64, error) {
+	switch e.Component {
+	case ComponentSwitch:
+		switch payload {
+		case payloadOn:
+			return 1, nil
+		case payloadOff:
+			return 0, nil
+		}
+		return 0, fmt.Errorf("unexpected switch payload %q", payload)
+	case ComponentSelect:
+		if v, ok := e.optionValue(payload); ok {
+			return float64(v), nil
+		}
+		return 0, fmt.Errorf("unknown option %q", payload)
+	default:
+		v, err := strconv.ParseFloat(strings.TrimSpace(payload), 64)
+		if err != nil {
+			return 0, err
+		}
+		if e.Component == ComponentNumber && (v < e.Min || v > e.Max) {
+			return 0, fmt.Errorf("value %v is out of range. Range %v:%v", v, e.Min, e.Max)
+		}
+		return v / e.scale(), nil
+	}
+}
+
+func (b *Bridge) discoveryConfig(d ecoflow.Device, e Entity) map[string]interface{} {
+	model := d.ProductName
+	if model == "" {
+		model = string(d.Type)
+	}
+	name := d.DeviceName
+	if name == "" {
+		name = "Ecoflow " + d.SN
+	}
+
+	config := map[string]interface{}{
+		"name":               e.Name,
+		"unique_id":          nodeId(d.SN) + "_" + e.Key,
+		"object_id":          nodeId(d.SN) + "_" + e.Key,
+		"state_topic":        b.stateTopic(d.SN, e),
+		"availability_topic": b.availabilityTopic(d.SN),
+		"device": map[string]interface{}{
+			"identifiers":   []string{nodeId(d.SN)},
+			"name":          name,
+			"manufacturer":  "EcoFlow",
+			"model":         model,
+			"serial_number": d.SN,
+		},
+	}
+	if e.Unit != "" {
+		config["unit_of_measurement"] = e.Unit
+	}
+	if e.DeviceClass != "" {
+		config["device_class"] = e.DeviceClass
+	}
+	if e.StateClass != "" {
+		config["state_class"] = e.StateClass
+	}
+	if e.Command != nil {
+		config["command_topic"] = b.commandTopic(d.SN, e)
+	}
+	switch e.Component {
+	case ComponentSwitch:
+		config["payload_on"] = payloadOn
+		config["payload_off"] = payloadOff
+	case ComponentNumber:
+		config["min"] = e.Min
+		config["max"] = e.Max
+		config["step"] = e.Step
+	case ComponentSelect:
+		options := make([]string, 0, len(e.Options))
+		for _, o := range e.Options {
+			options = append(options, o.Label)
+		}
+		config["options"] = options
+	}
+	return config
+}
+
+func (b *Bridge) publish(topic, payload string) {
+	token := b.local.Publish(topic, 1, true, payload)
+	if token.Wait() && token.Error() != nil {
+		slog.Error("Unable to publish", "topic", topic, "error", token.Error())
+	}
+}
+
+func nodeId(sn string) string {
+	return "ecoflow_" + strings.ToLower(sn)
+}
+
+func (b *Bridge) discoveryTopic(sn string, e Entity) string {
+	return fmt.Sprintf("%s/%s/%s/%s/config", b.config.DiscoveryPrefix, e.Component, nodeId(sn), e.Key)
+}
+
+func (b *Bridge) statusTopic() string {
+	return b.config.DiscoveryPrefix + "/status"
+}
+
+func (b *Bridge) availabilityTopic(sn string) string {
+	return fmt.Sprintf("%s/%s/availability", b.config.TopicPrefix, sn)
+}
+
+func (b *Bridge) stateTopic(sn string, e Entity) string {
+	return fmt.Sprintf("%s/%s/%s/state", b.config.TopicPrefix, sn, e.Key)
+}
+
+func (b *Bridge) commandTopic(sn string, e Entity) string {
+	return fmt.Sprintf("%s/%s/%s/set", b.config.TopicPrefix, sn, e.Key)
+}
@@ -0,0 +1,193 @@
+package homeassistant
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/tess1o/go-ecoflow"
+)
+
+type doneToken struct{}
+
+func (doneToken) Wait() bool                     { return true }
+func (doneToken) WaitTimeout(time.Duration) bool { return true }
+func (doneToken) Done() <-chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
+func (doneToken) Error() error { return nil }
+
+type message struct {
+	mqtt.Message
+	topic   string
+	payload []byte
+}
+
+func (m message) Topic() string   { return m.topic }
+func (m message) Payload() []byte { return m.payload }
+
+// fakeBroker records published messages and subscriptions
+type fakeBroker struct {
+	mqtt.Client
+	mu            sync.Mutex
+	published     map[string]string
+	subscriptions map[string]mqtt.MessageHandler
+}
+
+func newFakeBroker() *fakeBroker {
+	return &fakeBroker{published: make(map[string]string), subscriptions: make(map[string]mqtt.MessageHandler)}
+}
+
+func (f *fakeBroker) Publish(topic string, _ byte, _ bool, payload interface{}) mqtt.Token {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.published[topic] = payload.(string)
+	return doneToken{}
+}
+
+func (f *fakeBroker) Subscribe(topic string, _ byte, callback mqtt.MessageHandler) mqtt.Token {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.subscriptions[topic] = callback
+	return doneToken{}
+}
+
+func (f *fakeBroker) get(topic string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.published[topic]
+}
+
+func (f *fakeBroker) subscribed(topic string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.subscriptions[topic]
+	return ok
+}
+
+// reset forgets the published messages and the subscriptions like a restarted broker
+func (f *fakeBroker) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.published = make(map[string]string)
+	f.subscriptions = make(map[string]mqtt.MessageHandler)
+}
+
+func TestBridge(t *testing.T) {
+	var setRequest map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &setRequest)
+		_, _ = w.Write([]byte(`{"code":"0","message":"Success"}`))
+	}))
+	defer server.Close()
+
+	broker := newFakeBroker()
+	client := ecoflow.NewEcoflowClient("access", "secret", ecoflow.WithBaseUrl(server.URL))
+	bridge := NewBridge(broker, client, Config{})
+
+	bridge.UpdateDevices([]ecoflow.Device{{
+		DeviceInfo: ecoflow.DeviceInfo{SN: "R331ZEB4ZEAL0528", Online: 1, ProductName: "DELTA 2"},
+		Type:       ecoflow.DeviceTypePowerStation,
+	}})
+	bridge.Update(ecoflow.DeviceSnapshot{
+		Sn:         "R331ZEB4ZEAL0528",
+		DeviceType: ecoflow.DeviceTypePowerStation,
+		Params: map[string]interface{}{
+			"pd.soc":             float64(87),
+			"mppt.cfgAcEnabled":  float64(1),
+			"mppt.cfgAcXboost":   float64(0),
+			"mppt.cfgAcOutFreq":  float64(1),
+			"mppt.cfgAcOutVol":   float64(220),
+			"bms_bmsStatus.temp": float64(25),
+		},
+	})
+
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(broker.published["homeassistant/switch/ecoflow_r331zeb4zeal0528/ac_enabled/config"]), &config); err != nil {
+		t.Fatalf("discovery config is not published: %v", err)
+	}
+	if config["command_topic"] != "ecoflow/R331ZEB4ZEAL0528/ac_enabled/set" {
+		t.Errorf("unexpected command topic %v", config["command_topic"])
+	}
+	if broker.published["ecoflow/R331ZEB4ZEAL0528/availability"] != "online" {
+		t.Errorf("availability is not published")
+	}
+	if broker.published["ecoflow/R331ZEB4ZEAL0528/ac_enabled/state"] != "ON" {
+		t.Errorf("unexpected AC state %q", broker.published["ecoflow/R331ZEB4ZEAL0528/ac_enabled/state"])
+	}
+	if broker.published["ecoflow/R331ZEB4ZEAL0528/soc/state"] != "87" {
+		t.Errorf("unexpected soc state %q", broker.published["ecoflow/R331ZEB4ZEAL0528/soc/state"])
+	}
+
+	handler, ok := broker.subscriptions["ecoflow/R331ZEB4ZEAL0528/ac_enabled/set"]
+	if !ok {
+		t.Fatalf("command topic is not subscribed")
+	}
+	handler(broker, message{topic: "ecoflow/R331ZEB4ZEAL0528/ac_enabled/set", payload: []byte("OFF")})
+
+	if setRequest["operateType"] != "acOutCfg" {
+		t.Fatalf("unexpected set request %v", setRequest)
+	}
+	params := setRequest["params"].(map[string]interface{})
+	if params["enabled"] != float64(0) || params["out_voltage"] != float64(220) || params["out_freq"] != float64(1) {
+		t.Errorf("unexpected set request params %v", params)
+	}
+}
+
+func TestBridgeReconnect(t *testing.T) {
+	broker := newFakeBroker()
+	bridge := NewBridge(broker, ecoflow.NewEcoflowClient("access", "secret"), Config{})
+	if err := bridge.AddDevice(ecoflow.Device{DeviceInfo: ecoflow.DeviceInfo{SN: "R331ZEB4ZEAL0528", Online: 1}, Type: ecoflow.DeviceTypePowerStation}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	discoveryTopic := "homeassistant/switch/ecoflow_r331zeb4zeal0528/ac_enabled/config"
+	commandTopic := "ecoflow/R331ZEB4ZEAL0528/ac_enabled/set"
+
+	// the broker forgets the subscriptions and the retained messages
+	broker.reset()
+	bridge.OnConnect(broker)
+	if broker.get(discoveryTopic) == "" {
+		t.Errorf("discovery config is not published on connect")
+	}
+	if !broker.subscribed(commandTopic) {
+		t.Errorf("command topic is not subscribed on connect")
+	}
+	if !broker.subscribed("homeassistant/status") {
+		t.Fatalf("Home Assistant status topic is not subscribed")
+	}
+
+	// Home Assistant is restarted
+	handler := broker.subscriptions["homeassistant/status"]
+	broker.reset()
+	handler(broker, message{topic: "homeassistant/status", payload: []byte("offline")})
+	handler(broker, message{topic: "homeassistant/status", payload: []byte("online")})
+	deadline := time.Now().Add(time.Second)
+	for broker.get(discoveryTopic) == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if broker.get(discoveryTopic) == "" {
+		t.Errorf("discovery config is not published on Home Assistant birth message")
+	}
+}
+
+func TestCommandValue(t *testing.T) {
+	number := Entity{Component: ComponentNumber, Min: 0, Max: 100}
+	if _, err := commandValue(number, "101"); err == nil {
+		t.Errorf("expected out of range error, got nil")
+	}
+	sel := Entity{Component: ComponentSelect, Options: []Option{{"Cool", 0}, {"Heat", 1}}}
+	if v, err := commandValue(sel, "Heat"); err != nil || v != 1 {
+		t.Errorf("commandValue(Heat) = %v, %v; expected 1", v, err)
+	}
+	if _, err := commandValue(Entity{Component: ComponentSwitch}, "maybe"); err == nil {
+		t.Errorf("expected error for unexpected switch payload, got nil")
+	}
+}
@@ -0,0 +1,365 @@
+package homeassistant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+// Component is the Home Assistant entity platform
+type Component string
+
+const (
+	ComponentSensor Component = "sensor"
+	ComponentSwitch Component = "switch"
+	ComponentNumber Component = "number"
+	ComponentSelect Component = "select"
+)
+
+// Option is a select entity option: the label shown in Home Assistant and the raw parameter value
+type Option struct {
+	Label string
+	Value int
+}
+
+// CommandFunc applies the value received from Home Assistant using the library API.
+// The value is 1/0 for switches, the raw option value for selects and the number for numbers.
+// state contains the latest known device parameters, it can be used when the API requires several values at once
+type CommandFunc func(ctx context.Context, state State, value float64) error
+
+// Entity describes how a device parameter is exposed to Home Assistant
+type Entity struct {
+	// Key is the entity object id, unique for the device
+	Key       string
+	Name      string
+	Component Component
+	// StateKey is the device parameter the entity state is read from
+	StateKey string
+	// Scale is the multiplier applied to the raw parameter value (1 if not set), e.g. 0.1 for parameters in 0.1 W
+	Scale       float64
+	Unit        string
+	DeviceClass string
+	StateClass  string
+	// Min, Max and Step are used for numbers
+	Min, Max, Step float64
+	// Options are used for selects
+	Options []Option
+	// Command is nil for sensors
+	Command CommandFunc
+}
+
+// State is the latest known set of device parameters
+type State map[string]interface{}
+
+// Int returns the parameter value as int or def if the parameter is missing or is not a number
+func (s State) Int(key string, def int) int {
+	if v, ok := ecoflow.NumericValue(s[key]); ok {
+		return int(v)
+	}
+	return def
+}
+
+func (e Entity) scale() float64 {
+	if e.Scale == 0 {
+		return 1
+	}
+	return e.Scale
+}
+
+func (e Entity) optionLabel(value int) (string, bool) {
+	for _, o := range e.Options {
+		if o.Value == value {
+			return o.Label, true
+		}
+	}
+	return "", false
+}
+
+func (e Entity) optionValue(label string) (int, bool) {
+	for _, o := range e.Options {
+		if o.Label == label {
+			return o.Value, true
+		}
+	}
+	return 0, false
+}
+
+func powerSensor(key, name, stateKey string) Entity {
+	return Entity{Key: key, Name: name, Component: ComponentSensor, StateKey: stateKey, Unit: "W", DeviceClass: "power", StateClass: "measurement"}
+}
+
+func batterySensor(key, name, stateKey string) Entity {
+	return Entity{Key: key, Name: name, Component: ComponentSensor, StateKey: stateKey, Unit: "%", DeviceClass: "battery", StateClass: "measurement"}
+}
+
+func temperatureSensor(key, name, stateKey string) Entity {
+	return Entity{Key: key, Name: name, Component: ComponentSensor, StateKey: stateKey, Unit: "°C", DeviceClass: "temperature", StateClass: "measurement"}
+}
+
+func switcher(value float64) ecoflow.SettingSwitcher {
+	if value != 0 {
+		return ecoflow.SettingEnabled
+	}
+	return ecoflow.SettingDisabled
+}
+
+// checkResponse converts an unsuccessful response to an error
+func checkResponse(resp *ecoflow.CmdSetResponse, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp != nil && resp.Code != "0" {
+		return fmt.Errorf("can't set parameter, error code: %s, error message: %s", resp.Code, resp.Message)
+	}
+	return nil
+}
+
+// Entities returns the Home Assistant entities for the device. Unknown device types don't have entities
+func Entities(client *ecoflow.Client, device ecoflow.Device) []Entity {
+	switch device.Type {
+	case ecoflow.DeviceTypePowerStation:
+		return powerStationEntities(client.GetPowerStation(device.SN))
+	case ecoflow.DeviceTypePowerStationPro:
+		return powerStationProEntities(client.GetPowerStationPro(device.SN))
+	case ecoflow.DeviceTypeSmartPlug:
+		return smartPlugEntities(client.GetSmartPlug(device.SN))
+	case ecoflow.DeviceTypePowerStreamMicroInverter:
+		return powerStreamEntities(client.GetPowerStreamMicroInverter(device.SN))
+	case ecoflow.DeviceTypeWaveAirConditioner:
+		return waveEntities(client.GetWaveAirConditioner(device.SN))
+	case ecoflow.DeviceTypeGlacier:
+		return glacierEntities(client.GetGlacier(device.SN))
+	}
+	return nil
+}
+
+func powerStationEntities(ps *ecoflow.PowerStation) []Entity {
+	return []Entity{
+		batterySensor("soc", "Battery level", "pd.soc"),
+		powerSensor("input_watts", "Total input power", "pd.wattsInSum"),
+		powerSensor("output_watts", "Total output power", "pd.wattsOutSum"),
+		powerSensor("ac_input_watts", "AC input power", "inv.inputWatts"),
+		powerSensor("ac_output_watts", "AC output power", "inv.outputWatts"),
+		powerSensor("solar_input_watts", "Solar input power", "mppt.inWatts"),
+		temperatureSensor("battery_temperature", "Battery temperature", "bms_bmsStatus.temp"),
+		{
+			Key: "ac_enabled", Name: "AC output", Component: ComponentSwitch, StateKey: "mppt.cfgAcEnabled",
+			Command: func(ctx context.Context, state State, value float64) error {
+				// all AC parameters must be sent, the current values are used for the other ones
+				return checkResponse(ps.SetAcEnabled(ctx, switcher(value),
+					ecoflow.SettingSwitcher(state.Int("mppt.cfgAcXboost", int(ecoflow.SettingEnabled))),
+					ecoflow.GridFrequency(state.Int("mppt.cfgAcOutFreq", int(ecoflow.GridFrequency50Hz))),
+					state.Int("mppt.cfgAcOutVol", 230)))
+			},
+		},
+		{
+			Key: "dc_enabled", Name: "DC output", Component: ComponentSwitch, StateKey: "pd.dcOutState",
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(ps.SetDcSwitch(ctx, switcher(value)))
+			},
+		},
+		{
+			Key: "car_charger_enabled", Name: "Car charger", Component: ComponentSwitch, StateKey: "mppt.carState",
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(ps.SetCarChargerSwitch(ctx, switcher(value)))
+			},
+		},
+		{
+			Key: "max_charge_soc", Name: "Charge limit", Component: ComponentNumber, StateKey: "bms_emsStatus.maxChargeSoc",
+			Unit: "%", Min: 50, Max: 100, Step: 1,
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(ps.SetMaxChargeSoC(ctx, int(value)))
+			},
+		},
+		{
+			Key: "min_discharge_soc", Name: "Discharge limit", Component: ComponentNumber, StateKey: "bms_emsStatus.minDsgSoc",
+			Unit: "%", Min: 0, Max: 30, Step: 1,
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(ps.SetMinDischargeSoC(ctx, int(value)))
+			},
+		},
+		{
+			Key: "ac_charge_watts", Name: "AC charging power", Component: ComponentNumber, StateKey: "mppt.cfgChgWatts",
+			Unit: "W", DeviceClass: "power", Min: 200, Max: 1200, Step: 100,
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(ps.SetAcChargingSettings(ctx, int(value), ecoflow.SettingDisabled))
+			},
+		},
+	}
+}
+
+func powerStationProEntities(ps *ecoflow.PowerStationPro) []Entity {
+	return []Entity{
+		batterySensor("soc", "Battery level", "pd.soc"),
+		powerSensor("input_watts", "Total input power", "pd.wattsInSum"),
+		powerSensor("output_watts", "Total output power", "pd.wattsOutSum"),
+		{
+			Key: "car_charger_enabled", Name: "Car charger", Component: ComponentSwitch, StateKey: "mppt.carState",
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(ps.SetCarChargerSwitch(ctx, switcher(value)))
+			},
+		},
+		{
+			Key: "ac_enabled", Name: "AC output", Component: ComponentSwitch, StateKey: "inv.cfgAcEnabled",
+			Command: func(ctx context.Context, state State, value float64) error {
+				return checkResponse(ps.SetAcDischarge(ctx, switcher(value), ecoflow.SettingSwitcher(state.Int("inv.cfgAcXboost", 0))))
+			},
+		},
+		{
+			Key: "xboost_enabled", Name: "X-Boost", Component: ComponentSwitch, StateKey: "inv.cfgAcXboost",
+			Command: func(ctx context.Context, state State, value float64) error {
+				return checkResponse(ps.SetAcDischarge(ctx, ecoflow.SettingSwitcher(state.Int("inv.cfgAcEnabled", 0)), switcher(value)))
+			},
+		},
+		{
+			Key: "dc_enabled", Name: "DC output", Component: ComponentSwitch, StateKey: "pd.dcOutState",
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(ps.SetDcOutput(ctx, switcher(value)))
+			},
+		},
+		{
+			Key: "backup_reserve_soc", Name: "Backup reserve", Component: ComponentNumber, StateKey: "pd.bpPowerSoc",
+			Unit: "%", Min: 5, Max: 100, Step: 1,
+			Command: func(ctx context.Context, state State, value float64) error {
+				return checkResponse(ps.SetBackupReserve(ctx, ecoflow.SettingSwitcher(state.Int("pd.watthisconfig", 1)), int(value)))
+			},
+		},
+		{
+			Key: "max_charge_soc", Name: "Charge limit", Component: ComponentNumber, StateKey: "ems.maxChargeSoc",
+			Unit: "%", Min: 50, Max: 100, Step: 1,
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(ps.SetMaxChargeLevel(ctx, int(value)))
+			},
+		},
+		{
+			Key: "min_discharge_soc", Name: "Discharge limit", Component: ComponentNumber, StateKey: "ems.minDsgSoc",
+			Unit: "%", Min: 0, Max: 30, Step: 1,
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(ps.SetMinDischargeLevel(ctx, int(value)))
+			},
+		},
+	}
+}
+
+func smartPlugEntities(plug *ecoflow.SmartPlug) []Entity {
+	return []Entity{
+		{Key: "power", Name: "Power", Component: ComponentSensor, StateKey: "2_1.watts", Scale: 0.1, Unit: "W", DeviceClass: "power", StateClass: "measurement"},
+		temperatureSensor("temperature", "Temperature", "2_1.temp"),
+		{
+			Key: "relay", Name: "Relay", Component: ComponentSwitch, StateKey: "2_1.switchSta",
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(plug.SetRelaySwitch(ctx, switcher(value)))
+			},
+		},
+		{
+			Key: "brightness", Name: "Indicator brightness", Component: ComponentNumber, StateKey: "2_1.brightness",
+			Min: 0, Max: 1023, Step: 1,
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(plug.SetIndicatorBrightness(ctx, int(value)))
+			},
+		},
+	}
+}
+
+func powerStreamEntities(inv *ecoflow.PowerStreamMicroInverter) []Entity {
+	return []Entity{
+		{Key: "pv1_input_watts", Name: "PV1 input power", Component: ComponentSensor, StateKey: "20_1.pv1InputWatts", Scale: 0.1, Unit: "W", DeviceClass: "power", StateClass: "measurement"},
+		{Key: "pv2_input_watts", Name: "PV2 input power", Component: ComponentSensor, StateKey: "20_1.pv2InputWatts", Scale: 0.1, Unit: "W", DeviceClass: "power", StateClass: "measurement"},
+		{Key: "inverter_output_watts", Name: "Inverter output power", Component: ComponentSensor, StateKey: "20_1.invOutputWatts", Scale: 0.1, Unit: "W", DeviceClass: "power", StateClass: "measurement"},
+		batterySensor("battery_soc", "Battery level", "20_1.batSoc"),
+		{
+			Key: "custom_load_watts", Name: "Custom load power", Component: ComponentNumber, StateKey: "20_1.permanentWatts",
+			Scale: 0.1, Unit: "W", Min: 0, Max: 600, Step: 1,
+			Command: func(ctx context.Context, _ State, value float64) error {
+				// the value is in 0.1 W (see Scale), the setter expects W
+				return checkResponse(inv.SetCustomLoadPowerWatts(ctx, value/10))
+			},
+		},
+		{
+			Key: "supply_priority", Name: "Power supply priority", Component: ComponentSelect, StateKey: "20_1.supplyPriority",
+			Options: []Option{{"Power supply", 0}, {"Power storage", 1}},
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(inv.SetPowerSupplyPriority(ctx, int(value)))
+			},
+		},
+	}
+}
+
+func waveEntities(wave *ecoflow.WaveAirConditioner) []Entity {
+	return []Entity{
+		temperatureSensor("ambient_temperature", "Ambient temperature", "pd.envTemp"),
+		batterySensor("battery_soc", "Battery level", "bms.soc"),
+		{
+			Key: "main_mode", Name: "Mode", Component: ComponentSelect, StateKey: "pd.mainMode",
+			Options: []Option{
+				{"Cool", int(ecoflow.ConditionerMainModeCool)},
+				{"Heat", int(ecoflow.ConditionerMainModeHeat)},
+				{"Fan", int(ecoflow.ConditionerMainModeFan)},
+			},
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(wave.SetMainMode(ctx, ecoflow.ConditionerMainMode(value)))
+			},
+		},
+		{
+			Key: "fan_speed", Name: "Fan speed", Component: ComponentSelect, StateKey: "pd.fanValue",
+			Options: []Option{
+				{"Low", int(ecoflow.ConditionerWindSpeedLow)},
+				{"Medium", int(ecoflow.ConditionerWindSpeedMedium)},
+				{"High", int(ecoflow.ConditionerWindSpeedHigh)},
+			},
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(wave.SetWindSpeed(ctx, ecoflow.ConditionerWindSpeed(value)))
+			},
+		},
+		{
+			Key: "target_temperature", Name: "Target temperature", Component: ComponentNumber, StateKey: "pd.setTemp",
+			Unit: "°C", DeviceClass: "temperature", Min: 16, Max: 30, Step: 1,
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(wave.SetTemperature(ctx, int(value)))
+			},
+		},
+	}
+}
+
+func glacierEntities(g *ecoflow.Glacier) []Entity {
+	// all three temperatures must be sent, the current values are used for the other ones
+	setTemperature := func(zone string) CommandFunc {
+		return func(ctx context.Context, state State, value float64) error {
+			tmpR, tmpL, tmpM := state.Int("pd.tmpRSet", 0), state.Int("pd.tmpLSet", 0), state.Int("pd.tmpMSet", 0)
+			switch zone {
+			case "R":
+				tmpR = int(value)
+			case "L":
+				tmpL = int(value)
+			case "M":
+				tmpM = int(value)
+			}
+			return checkResponse(g.SetTemperature(ctx, tmpR, tmpL, tmpM))
+		}
+	}
+
+	return []Entity{
+		temperatureSensor("left_temperature", "Left zone temperature", "pd.tmpL"),
+		temperatureSensor("right_temperature", "Right zone temperature", "pd.tmpR"),
+		batterySensor("battery_soc", "Battery level", "bms_bmsStatus.soc"),
+		{
+			Key: "left_target_temperature", Name: "Left zone target temperature", Component: ComponentNumber, StateKey: "pd.tmpLSet",
+			Unit: "°C", DeviceClass: "temperature", Min: -25, Max: 10, Step: 1, Command: setTemperature("L"),
+		},
+		{
+			Key: "right_target_temperature", Name: "Right zone target temperature", Component: ComponentNumber, StateKey: "pd.tmpRSet",
+			Unit: "°C", DeviceClass: "temperature", Min: -25, Max: 10, Step: 1, Command: setTemperature("R"),
+		},
+		{
+			Key: "combined_target_temperature", Name: "Target temperature (no partition)", Component: ComponentNumber, StateKey: "pd.tmpMSet",
+			Unit: "°C", DeviceClass: "temperature", Min: -25, Max: 10, Step: 1, Command: setTemperature("M"),
+		},
+		{
+			Key: "eco_mode", Name: "ECO mode", Component: ComponentSwitch, StateKey: "pd.coolMode",
+			Command: func(ctx context.Context, _ State, value float64) error {
+				return checkResponse(g.SetEcoMode(ctx, ecoflow.GlacierModeType(switcher(value))))
+			},
+		},
+	}
+}
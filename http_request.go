@@ -10,6 +10,7 @@ import (
 	"log/slog"
 	"math/rand"
 	"net/http"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -67,6 +68,28 @@ func NewHttpRequest(httpClient *http.Client, method string, uri string, params m
 // For GET requests the parameters are added to GET request query.
 // The query has predefined rules which are described here: https://developer-eu.ecoflow.com/us/document/generalInfo
 func (r *HttpRequest) Execute(ctx context.Context) ([]byte, error) {
+	httpReq, err := r.Sign(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client := r.httpClient
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(fmt.Sprintf("response status is failed|url=%s, statusCode=%s", httpReq.URL, resp.Status))
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Sign returns the request with all the headers required by Ecoflow Rest API, the request is not sent.
+// It's used by Execute and by the dry-run mode (see WithDryRun)
+func (r *HttpRequest) Sign(ctx context.Context) (*http.Request, error) {
 	signParams := r.getSignParameters()
 	requestURI := r.uri + "?" + signParams.queryParams
 
@@ -108,19 +131,7 @@ func (r *HttpRequest) Execute(ctx context.Context) ([]byte, error) {
 	httpReq.Header.Add(nonceHeader, signParams.nonce)
 	httpReq.Header.Add(timestampHeader, signParams.timestamp)
 	httpReq.Header.Add(signHeader, signParams.sign)
-
-	client := r.httpClient
-	if client == nil {
-		client = &http.Client{}
-	}
-
-	resp, err := client.Do(httpReq)
-	if err != nil {
-		return nil, err
-	} else if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(fmt.Sprintf("response status is failed|url=%s, statusCode=%s", requestURI, resp.Status))
-	}
-	return io.ReadAll(resp.Body)
+	return httpReq, nil
 }
 
 type signParameters struct {
@@ -206,6 +217,7 @@ func generateQueryParams(data map[string]interface{}) string {
 // - int: Append the key-value pair to the result slice after converting the int to a string.
 // - float64: Append the key-value pair to the result slice after converting the float64 to a string.
 // - bool: Append the key-value pair to the result slice after converting the bool to a string.
+// - other slices, maps with string keys and numbers: processed via reflection the same way as above.
 func processValue(prefix string, value interface{}) []string {
 	var result []string
 	switch v := value.(type) {
@@ -229,6 +241,30 @@ func processValue(prefix string, value interface{}) []string {
 		result = append(result, prefix+"="+strconv.FormatFloat(v, 'f', -1, 64))
 	case bool:
 		result = append(result, prefix+"="+strconv.FormatBool(v))
+	default:
+		// typed slices, maps and numbers (e.g. []int, []map[string]interface{}, SettingSwitcher) are processed the same way
+		rv := reflect.ValueOf(value)
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				result = append(result, processValue(prefix+"["+strconv.Itoa(i)+"]", rv.Index(i).Interface())...)
+			}
+		case reflect.Map:
+			if rv.Type().Key().Kind() == reflect.String {
+				iter := rv.MapRange()
+				for iter.Next() {
+					result = append(result, processValue(prefix+"."+iter.Key().String(), iter.Value().Interface())...)
+				}
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			result = append(result, prefix+"="+strconv.FormatInt(rv.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			result = append(result, prefix+"="+strconv.FormatUint(rv.Uint(), 10))
+		case reflect.Float32:
+			result = append(result, prefix+"="+strconv.FormatFloat(rv.Float(), 'f', -1, 32))
+		case reflect.String:
+			result = append(result, prefix+"="+rv.String())
+		}
 	}
 	return result
 }
@@ -125,6 +125,20 @@ func TestGenerateQueryParams(t *testing.T) {
 			},
 			expectedOut: "deviceInfo.id=1&deviceList[0].id=1&deviceList[1].id=2&ids[0]=1&ids[1]=2&ids[2]=3&name=demo1",
 		},
+		{
+			name: "Typed nested arrays",
+			input: map[string]interface{}{
+				"cmdSet": 11,
+				"id":     18,
+				"cfgList": []map[string]interface{}{
+					{"linkMark": 1, "linkCh": 1},
+					{"linkMark": 1, "linkCh": 0},
+				},
+				"chSta": []int{1, 0},
+				"eps":   SettingEnabled,
+			},
+			expectedOut: "cfgList[0].linkCh=1&cfgList[0].linkMark=1&cfgList[1].linkCh=0&cfgList[1].linkMark=1&chSta[0]=1&chSta[1]=0&cmdSet=11&eps=1&id=18",
+		},
 		{
 			name: "NestedData",
 			input: map[string]interface{}{
@@ -0,0 +1,151 @@
+package influx
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+var snapshot = ecoflow.DeviceSnapshot{
+	Sn:         "R331ZEB4ZEAL0528",
+	DeviceType: ecoflow.DeviceTypePowerStation,
+	Timestamp:  time.UnixMilli(1700000000123),
+	Params: map[string]interface{}{
+		"pd.soc":            float64(55),
+		"pd.wattsOutSum":    float64(120),
+		"pd.model":          "Delta 2",
+		"bms_bmsStatus.soc": float64(54.5),
+		"inv.reserved":      []interface{}{float64(0), float64(0)},
+		"inv.outTemp":       math.NaN(),
+		"inv.inputWatts":    math.Inf(1),
+	},
+}
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoder  Encoder
+		expected []string
+	}{
+		{
+			name:    "Default",
+			encoder: Encoder{},
+			expected: []string{
+				`ecoflow_bms_bmsStatus,sn=R331ZEB4ZEAL0528,device_type=power_station soc=54.5 1700000000123000000`,
+				`ecoflow_pd,sn=R331ZEB4ZEAL0528,device_type=power_station model="Delta 2",soc=55,wattsOutSum=120 1700000000123000000`,
+			},
+		},
+		{
+			name:    "Numeric only with custom measurement",
+			encoder: Encoder{NumericOnly: true, Measurements: map[string]string{"bms_bmsStatus": "battery"}},
+			expected: []string{
+				`battery,sn=R331ZEB4ZEAL0528,device_type=power_station soc=54.5 1700000000123000000`,
+				`ecoflow_pd,sn=R331ZEB4ZEAL0528,device_type=power_station soc=55,wattsOutSum=120 1700000000123000000`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := tt.encoder.Encode(snapshot)
+			if strings.Join(actual, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("got:\n%s\nexpected:\n%s", strings.Join(actual, "\n"), strings.Join(tt.expected, "\n"))
+			}
+		})
+	}
+}
+
+func TestWriterRetry(t *testing.T) {
+	var requests atomic.Int32
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Authorization") != "Token secret" {
+			t.Errorf("unexpected authorization header %q", r.Header.Get("Authorization"))
+		}
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	writer, err := NewWriter(WriterConfig{
+		WriteUrl:      server.URL + "/api/v2/write?org=home&bucket=ecoflow&precision=ns",
+		Token:         "secret",
+		HttpClient:    server.Client(),
+		RetryInterval: time.Millisecond,
+		Encoder:       &Encoder{NumericOnly: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writer.Write(snapshot)
+
+	if err = writer.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected 2 requests, got %d", requests.Load())
+	}
+	if strings.Count(body, "\n") != 2 {
+		t.Errorf("expected 2 lines, got %q", body)
+	}
+}
+
+func TestWriterClientError(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	writer, err := NewWriter(WriterConfig{WriteUrl: server.URL, RetryInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writer.Write(snapshot)
+
+	if err = writer.Flush(context.Background()); err == nil {
+		t.Fatalf("expected error for bad request, got nil")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("bad request must not be retried, got %d requests", requests.Load())
+	}
+}
+
+func TestWriterNoRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	writer, err := NewWriter(WriterConfig{WriteUrl: server.URL, MaxRetries: NoRetries, RetryInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	writer.Write(snapshot)
+
+	if err = writer.Flush(context.Background()); err == nil {
+		t.Fatalf("expected error for unavailable server, got nil")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("retries are disabled, got %d requests", requests.Load())
+	}
+	if _, err = NewWriter(WriterConfig{WriteUrl: server.URL, MaxRetries: -2}); err == nil {
+		t.Errorf("expected error for negative max retries")
+	}
+}
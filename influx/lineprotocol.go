@@ -0,0 +1,110 @@
+// Package influx converts device snapshots to InfluxDB line protocol and writes them to InfluxDB
+// (or any compatible time-series database, e.g. VictoriaMetrics) via HTTP.
+package influx
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+const defaultMeasurementPrefix = "ecoflow_"
+
+// Encoder converts device snapshots to InfluxDB line protocol.
+// Every module of the device parameters is written to its own measurement: "pd.soc" and "pd.wattsOutSum" are written as
+// fields "soc" and "wattsOutSum" of the "ecoflow_pd" measurement. The device serial number and type are written as tags.
+type Encoder struct {
+	// MeasurementPrefix is added to the module name to get the measurement name, "ecoflow_" by default
+	MeasurementPrefix string
+	// Measurements maps the module name to a custom measurement name, e.g. {"bms_bmsStatus": "battery"}.
+	// The parameters without module use "" key, the measurement is "ecoflow" by default
+	Measurements map[string]string
+	// NumericOnly skips non-numeric parameters. Otherwise, strings are written as string fields.
+	// Arrays and nested objects are always skipped
+	NumericOnly bool
+	// Filter selects the parameters to write, all parameters are written if nil
+	Filter *ecoflow.KeyFilter
+}
+
+// Encode returns the lines (without trailing new line) for the snapshot, one line per measurement.
+// The snapshot timestamp is written in nanoseconds.
+func (e *Encoder) Encode(s ecoflow.DeviceSnapshot) []string {
+	fields := make(map[string][]string)
+	for k, v := range s.Params {
+		if !e.Filter.Match(k) {
+			continue
+		}
+		module, field := ecoflow.SplitQuotaKey(k)
+		value, ok := e.fieldValue(v)
+		if !ok {
+			continue
+		}
+		measurement := e.measurement(module)
+		fields[measurement] = append(fields[measurement], escape(field, keyEscaper)+"="+value)
+	}
+
+	measurements := make([]string, 0, len(fields))
+	for m := range fields {
+		measurements = append(measurements, m)
+	}
+	sort.Strings(measurements)
+
+	tags := ",sn=" + escape(s.Sn, keyEscaper)
+	if s.DeviceType != "" {
+		tags += ",device_type=" + escape(string(s.DeviceType), keyEscaper)
+	}
+	timestamp := ""
+	if !s.Timestamp.IsZero() {
+		timestamp = " " + strconv.FormatInt(s.Timestamp.UnixNano(), 10)
+	}
+
+	lines := make([]string, 0, len(measurements))
+	for _, m := range measurements {
+		f := fields[m]
+		sort.Strings(f)
+		lines = append(lines, escape(m, measurementEscaper)+tags+" "+strings.Join(f, ",")+timestamp)
+	}
+	return lines
+}
+
+func (e *Encoder) measurement(module string) string {
+	if m, ok := e.Measurements[module]; ok {
+		return m
+	}
+	prefix := e.MeasurementPrefix
+	if prefix == "" {
+		prefix = defaultMeasurementPrefix
+	}
+	if module == "" {
+		return strings.TrimSuffix(prefix, "_")
+	}
+	return prefix + module
+}
+
+// fieldValue formats the field value. All numbers are written as floats, so the field type doesn't depend on the value.
+// NaN and infinite values are skipped, the line protocol can't represent them
+func (e *Encoder) fieldValue(v interface{}) (string, bool) {
+	if f, ok := ecoflow.NumericValue(v); ok {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	}
+	if s, ok := v.(string); ok && !e.NumericOnly {
+		return `"` + stringEscaper.Replace(s) + `"`, true
+	}
+	return "", false
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	keyEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	stringEscaper      = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+func escape(s string, r *strings.Replacer) string {
+	return r.Replace(s)
+}
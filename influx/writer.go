@@ -0,0 +1,193 @@
+package influx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+const (
+	defaultBatchSize     = 500
+	defaultFlushInterval = 10 * time.Second
+	defaultMaxRetries    = 3
+	defaultRetryInterval = time.Second
+)
+
+// NoRetries is the WriterConfig.MaxRetries value that disables the retries, 0 means the default number of retries
+const NoRetries = -1
+
+type WriterConfig struct {
+	// WriteUrl is the full write endpoint url, e.g.
+	// InfluxDB 2: http://localhost:8086/api/v2/write?org=home&bucket=ecoflow&precision=ns
+	// InfluxDB 1: http://localhost:8086/write?db=ecoflow&precision=ns
+	// VictoriaMetrics: http://localhost:8428/write
+	WriteUrl string
+	// Token is sent in "Authorization: Token <token>" header if not empty
+	Token string
+	// HttpClient is used to send the requests, http.DefaultClient if nil
+	HttpClient *http.Client
+	// BatchSize is the number of lines that triggers writing, 500 by default
+	BatchSize int
+	// FlushInterval is the maximum time the lines are kept in the buffer when Run is used, 10 seconds by default
+	FlushInterval time.Duration
+	// MaxRetries is the number of retries for the failed writes (network errors, 429 and 5xx responses), 3 by default.
+	// Use NoRetries (-1) to disable the retries
+	MaxRetries int
+	// RetryInterval is the delay before the first retry, it's doubled for every next retry. 1 second by default
+	RetryInterval time.Duration
+	// Encoder converts snapshots to lines, the default encoder is used if nil
+	Encoder *Encoder
+}
+
+// Writer buffers the lines and writes them in batches. It can be used as ecoflow.PollerConfig.OnSnapshot handler
+// or with ecoflow.MqttClient.SubscribeForSnapshots (via Write method)
+type Writer struct {
+	config WriterConfig
+	mu     sync.Mutex
+	buffer []string
+	// flushMu makes sure that batches are sent one by one and in order
+	flushMu sync.Mutex
+}
+
+func NewWriter(config WriterConfig) (*Writer, error) {
+	if config.WriteUrl == "" {
+		return nil, errors.New("write url is mandatory")
+	}
+	if config.HttpClient == nil {
+		config.HttpClient = http.DefaultClient
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultFlushInterval
+	}
+	switch {
+	case config.MaxRetries == NoRetries:
+		config.MaxRetries = 0
+	case config.MaxRetries < 0:
+		return nil, errors.New("max retries must not be negative, use NoRetries to disable the retries")
+	case config.MaxRetries == 0:
+		config.MaxRetries = defaultMaxRetries
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaultRetryInterval
+	}
+	if config.Encoder == nil {
+		config.Encoder = &Encoder{}
+	}
+	return &Writer{config: config}, nil
+}
+
+// Write encodes the snapshot and adds the lines to the buffer. The buffer is written in the background when it's full,
+// errors are logged
+func (w *Writer) Write(s ecoflow.DeviceSnapshot) {
+	w.mu.Lock()
+	w.buffer = append(w.buffer, w.config.Encoder.Encode(s)...)
+	full := len(w.buffer) >= w.config.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		go func() {
+			if err := w.Flush(context.Background()); err != nil {
+				slog.Error("Unable to write lines", "error", err)
+			}
+		}()
+	}
+}
+
+// Run flushes the buffer every FlushInterval until the context is cancelled. The buffer is flushed before returning
+func (w *Writer) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), w.config.FlushInterval)
+			defer cancel()
+			return w.Flush(flushCtx)
+		case <-ticker.C:
+			if err := w.Flush(ctx); err != nil {
+				slog.Error("Unable to write lines", "error", err)
+			}
+		}
+	}
+}
+
+// Flush writes all buffered lines in batches of BatchSize. The batch that can't be written after all retries is dropped
+func (w *Writer) Flush(ctx context.Context) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	w.mu.Lock()
+	lines := w.buffer
+	w.buffer = nil
+	w.mu.Unlock()
+
+	var errs []error
+	for len(lines) > 0 {
+		n := min(len(lines), w.config.BatchSize)
+		if err := w.writeWithRetry(ctx, lines[:n]); err != nil {
+			errs = append(errs, err)
+		}
+		lines = lines[n:]
+	}
+	return errors.Join(errs...)
+}
+
+func (w *Writer) writeWithRetry(ctx context.Context, lines []string) error {
+	body := []byte(strings.Join(lines, "\n") + "\n")
+	delay := w.config.RetryInterval
+	var err error
+	for attempt := 0; attempt <= w.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+		var retry bool
+		retry, err = w.write(ctx, body)
+		if err == nil || !retry {
+			return err
+		}
+		slog.Debug("Write failed, retrying", "attempt", attempt+1, "error", err)
+	}
+	return err
+}
+
+// write sends the batch, the returned bool is true if the error is temporary and the request can be retried
+func (w *Writer) write(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.config.WriteUrl, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.config.Token != "" {
+		req.Header.Set("Authorization", "Token "+w.config.Token)
+	}
+
+	resp, err := w.config.HttpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("write failed|url=%s, statusCode=%s, response=%s", w.config.WriteUrl, resp.Status, strings.TrimSpace(string(message)))
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
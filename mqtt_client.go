@@ -9,6 +9,7 @@ import (
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/google/uuid"
 	"io"
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -27,11 +28,14 @@ type MqttClientConfiguration struct {
 	OnConnectionLost     mqtt.ConnectionLostHandler
 	OnReconnect          mqtt.ReconnectHandler
 	MaxReconnectInterval time.Duration
+	// DryRun enables the dry-run mode for all the commands published by the client, see PublishCommand
+	DryRun bool
 }
 
 type MqttClient struct {
 	Client           mqtt.Client
 	connectionConfig *MqttConnectionConfig
+	dryRun           bool
 }
 
 // NewMqttClient creates a new MQTT client using email and password
@@ -66,7 +70,7 @@ func NewMqttClient(ctx context.Context, config MqttClientConfiguration) (*MqttCl
 	if config.MaxReconnectInterval != 0 {
 		opts.MaxReconnectInterval = config.MaxReconnectInterval
 	}
-	return &MqttClient{Client: mqtt.NewClient(opts), connectionConfig: c}, nil
+	return &MqttClient{Client: mqtt.NewClient(opts), connectionConfig: c, dryRun: config.DryRun}, nil
 }
 
 // GetMqttCredentials get the MQTT credentials using email and password (the same as you use to log in to your Ecoflow app).
@@ -190,3 +194,17 @@ func (m *MqttClient) SubscribeToTopics(topics []string, callback mqtt.MessageHan
 	token.Wait()
 	return nil
 }
+
+// SubscribeForSnapshots Subscribe to the device's topic and convert every received message to a DeviceSnapshot.
+// Messages that can't be parsed are logged and skipped.
+// The client must be connected to the broker before subscribing to the topic
+func (m *MqttClient) SubscribeForSnapshots(deviceSn string, handler func(DeviceSnapshot)) error {
+	return m.SubscribeForParameters(deviceSn, func(client mqtt.Client, msg mqtt.Message) {
+		snapshot, err := ParseMqttSnapshot(deviceSn, msg.Payload())
+		if err != nil {
+			slog.Error("Unable to parse mqtt message", "topic", msg.Topic(), "error", err)
+			return
+		}
+		handler(*snapshot)
+	})
+}
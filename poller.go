@@ -0,0 +1,121 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	PollOperationDeviceList    = "device_list"
+	PollOperationAllParameters = "all_parameters"
+)
+
+// PollerConfig is the configuration of the REST API poller.
+// Only Interval and OnSnapshot are mandatory, other handlers can be nil
+type PollerConfig struct {
+	Interval time.Duration
+	// Devices is the list of serial numbers to poll. If empty, all linked online devices are polled
+	Devices []string
+	// OnSnapshot is executed for every device after its parameters are received
+	OnSnapshot func(DeviceSnapshot)
+	// OnDevices is executed after the list of linked devices is received (once per poll)
+	OnDevices func([]Device)
+	// OnRequest is executed after every REST API call, can be used to collect statistics.
+	// operation is one of PollOperationDeviceList or PollOperationAllParameters, sn is empty for the device list
+	OnRequest func(operation, sn string, duration time.Duration, err error)
+}
+
+// Poller periodically gets all parameters of the devices via REST API
+type Poller struct {
+	c      *Client
+	config PollerConfig
+}
+
+func (c *Client) NewPoller(config PollerConfig) (*Poller, error) {
+	if config.Interval <= 0 {
+		return nil, errors.New("interval must be positive")
+	}
+	if config.OnSnapshot == nil {
+		return nil, errors.New("OnSnapshot handler is mandatory")
+	}
+	return &Poller{c: c, config: config}, nil
+}
+
+// Run polls the devices immediately and then every Interval until the context is cancelled
+func (p *Poller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll gets the list of linked devices and all parameters for every selected device once.
+// Errors are reported via OnRequest handler, the devices with errors are skipped
+func (p *Poller) Poll(ctx context.Context) {
+	start := time.Now()
+	devices, err := p.c.Devices(ctx)
+	p.onRequest(PollOperationDeviceList, "", time.Since(start), err)
+	if err == nil && p.config.OnDevices != nil {
+		p.config.OnDevices(devices)
+	}
+
+	for _, d := range p.selectDevices(devices) {
+		if ctx.Err() != nil {
+			return
+		}
+		start = time.Now()
+		params, err := p.c.GetDeviceAllParameters(ctx, d.SN)
+		p.onRequest(PollOperationAllParameters, d.SN, time.Since(start), err)
+		if err != nil {
+			continue
+		}
+		p.config.OnSnapshot(DeviceSnapshot{
+			Sn:         d.SN,
+			DeviceType: d.Type,
+			Timestamp:  time.Now(),
+			Params:     params,
+		})
+	}
+}
+
+// selectDevices returns the devices configured in PollerConfig.Devices, or all online linked devices if nothing is configured.
+// The device type of the configured devices is taken from the device list (if available) or inferred from the serial number
+func (p *Poller) selectDevices(linked []Device) []Device {
+	if len(p.config.Devices) == 0 {
+		var online []Device
+		for _, d := range linked {
+			if d.IsOnline() {
+				online = append(online, d)
+			}
+		}
+		return online
+	}
+
+	bySn := make(map[string]Device, len(linked))
+	for _, d := range linked {
+		bySn[d.SN] = d
+	}
+	selected := make([]Device, 0, len(p.config.Devices))
+	for _, sn := range p.config.Devices {
+		d, ok := bySn[sn]
+		if !ok {
+			d = Device{DeviceInfo: DeviceInfo{SN: sn}, Type: InferDeviceType(sn, "")}
+		}
+		selected = append(selected, d)
+	}
+	return selected
+}
+
+func (p *Poller) onRequest(operation, sn string, duration time.Duration, err error) {
+	if p.config.OnRequest != nil {
+		p.config.OnRequest(operation, sn, duration, err)
+	}
+}
@@ -2,9 +2,6 @@ package ecoflow
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"time"
 )
 
 type PowerKit struct {
@@ -86,7 +83,7 @@ func (k *PowerKit) SetChargingSettings(ctx context.Context, chgPause, maxChgCurr
 // SetDischargingSettings Discharging settings(swSta: 0: off 1: on)
 // { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M1093-DCIN-CA7C3", "moduleType": 15362, "operateType": "dischgParaSet", "params": { "swSta": 0 } }
 // in documentation it says BBC_OUT (15363), in the json example it's still 15362. Next examples uses 15363...
-func (k *PowerKit) SetDischargingSettings(ctx context.Context, enabled PowerKitDcVoltage) (*CmdSetResponse, error) {
+func (k *PowerKit) SetDischargingSettings(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
 	params := make(map[string]interface{})
 	params["swSta"] = enabled
 	return k.setParameter(ctx, "dischgParaSet", PowerKitModuleTypeBbcOut, params)
@@ -260,25 +257,16 @@ func (k *PowerKit) GetAllParameters(ctx context.Context) (map[string]interface{}
 }
 
 func (k *PowerKit) setParameter(ctx context.Context, opType string, modType PowerKitModuleType, params map[string]interface{}) (*CmdSetResponse, error) {
-	cmdReq := powerKitSetCmdRequest{
-		Id:          fmt.Sprint(time.Now().UnixMilli()),
+	return k.setModuleParameter(ctx, k.moduleSn, opType, modType, params)
+}
+
+func (k *PowerKit) setModuleParameter(ctx context.Context, moduleSn string, opType string, modType PowerKitModuleType, params map[string]interface{}) (*CmdSetResponse, error) {
+	return k.c.SendCommand(ctx, Command{
+		Style:       CommandStyleModule,
 		Sn:          k.sn,
-		ModuleSn:    k.moduleSn,
-		ModuleType:  modType,
+		ModuleSn:    moduleSn,
 		OperateType: opType,
+		ModuleType:  ModuleType(modType),
 		Params:      params,
-	}
-
-	jsonData, err := json.Marshal(cmdReq)
-	if err != nil {
-		return nil, err
-	}
-
-	var req map[string]interface{}
-
-	err = json.Unmarshal(jsonData, &req)
-	if err != nil {
-		return nil, err
-	}
-	return k.c.SetDeviceParameter(ctx, req)
+	})
 }
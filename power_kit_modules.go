@@ -0,0 +1,601 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// powerKitUnchanged is sent for the parameters of a combined command that must not be changed
+const powerKitUnchanged = 255
+
+// powerKitModuleKeys maps the prefixes of the Power Kit quota keys to the module types, e.g. "bp5000" or "bbcin".
+// A prefix matches only if it's followed by digits, a delimiter or the end of the key (see powerKitModuleType)
+var powerKitModuleKeys = []struct {
+	prefix     string
+	moduleType PowerKitModuleType
+}{
+	{"bbcin", PowerKitModuleTypeBbcIn},
+	{"bbcout", PowerKitModuleTypeBbcOut},
+	{"iclow", PowerKitModuleTypeIcLow},
+	{"bp", PowerKitModuleTypeBp5000Bp2000},
+	{"ldac", PowerKitModuleTypeLdAc},
+	{"lddc", PowerKitModuleTypeLdDc},
+	{"wireless", PowerKitModuleTypeWireless},
+	{"gen", PowerKitModuleTypeGenerator},
+}
+
+func (t PowerKitModuleType) String() string {
+	switch t {
+	case PowerKitModuleTypeBbcIn:
+		return "BBC_IN"
+	case PowerKitModuleTypeBbcOut:
+		return "BBC_OUT"
+	case PowerKitModuleTypeIcLow:
+		return "IC_LOW"
+	case PowerKitModuleTypeBp5000Bp2000:
+		return "BP"
+	case PowerKitModuleTypeLdAc:
+		return "LD_AC"
+	case PowerKitModuleTypeLdDc:
+		return "LD_DC"
+	case PowerKitModuleTypeWireless:
+		return "Wireless"
+	case PowerKitModuleTypeGenerator:
+		return "GEN"
+	}
+	return fmt.Sprintf("PowerKitModuleType(%d)", int(t))
+}
+
+// PowerKitModule is a module found in the Power Kit parameters
+type PowerKitModule struct {
+	Type PowerKitModuleType
+	Sn   string
+	// Params are the raw parameters reported by the module
+	Params map[string]interface{}
+}
+
+// DecodePowerKitModules finds the modules in the Power Kit parameters (see GetAllParameters).
+// The parameters of the modules are grouped by the module kind, e.g. "bp5000": [{"sn": "M102Z3B4ZEA70076", "soc": 80, ...}],
+// a module object is recognized by its "sn" (or "moduleSn") field. The flattened keys like "bbcin.0.sn" are supported as well.
+// The modules are sorted by type and serial number
+func DecodePowerKitModules(params map[string]interface{}) []PowerKitModule {
+	found := make(map[string]*PowerKitModule)
+	add := func(moduleType PowerKitModuleType, m map[string]interface{}) {
+		sn, _ := m["sn"].(string)
+		if sn == "" {
+			sn, _ = m["moduleSn"].(string)
+		}
+		if sn == "" {
+			return
+		}
+		if v, ok := mapInt(m, "moduleType"); ok && isPowerKitModuleType(PowerKitModuleType(v)) {
+			moduleType = PowerKitModuleType(v)
+		}
+		found[sn] = &PowerKitModule{Type: moduleType, Sn: sn, Params: m}
+	}
+
+	for key, v := range params {
+		parts := strings.Split(key, ".")
+		moduleType, ok := powerKitModuleType(parts[0])
+		if !ok {
+			continue
+		}
+		switch value := v.(type) {
+		case []interface{}:
+			for _, item := range value {
+				if m, ok := item.(map[string]interface{}); ok {
+					add(moduleType, m)
+				}
+			}
+		case map[string]interface{}:
+			add(moduleType, value)
+		case string:
+			last := parts[len(parts)-1]
+			if len(parts) > 1 && (last == "sn" || last == "moduleSn") && value != "" && found[value] == nil {
+				found[value] = &PowerKitModule{Type: moduleType, Sn: value}
+			}
+		}
+	}
+
+	modules := make([]PowerKitModule, 0, len(found))
+	for _, m := range found {
+		modules = append(modules, *m)
+	}
+	sort.Slice(modules, func(i, j int) bool {
+		if modules[i].Type != modules[j].Type {
+			return modules[i].Type < modules[j].Type
+		}
+		return modules[i].Sn < modules[j].Sn
+	})
+	return modules
+}
+
+// powerKitModuleType returns the module type of the quota key. The rest of the key after the prefix must be empty or
+// start with a digit or a delimiter, so "bp5000" and "gen_1" are modules, but "bpPowerSoc" and "generalInfo" are not
+func powerKitModuleType(key string) (PowerKitModuleType, bool) {
+	key = strings.ToLower(key)
+	for _, m := range powerKitModuleKeys {
+		rest, ok := strings.CutPrefix(key, m.prefix)
+		if !ok {
+			continue
+		}
+		if rest == "" || (rest[0] >= '0' && rest[0] <= '9') || rest[0] == '_' || rest[0] == '-' {
+			return m.moduleType, true
+		}
+	}
+	return 0, false
+}
+
+func isPowerKitModuleType(t PowerKitModuleType) bool {
+	for _, m := range powerKitModuleKeys {
+		if m.moduleType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// PowerKitModules are the typed handles of the Power Kit modules, see GetModules
+type PowerKitModules struct {
+	All        []PowerKitModule
+	BbcIn      []*PowerKitBbcIn
+	BbcOut     []*PowerKitBbcOut
+	IcLow      []*PowerKitIcLow
+	Batteries  []*PowerKitBattery
+	LdAc       []*PowerKitLdAc
+	LdDc       []*PowerKitLdDc
+	Wireless   []*PowerKitWireless
+	Generators []*PowerKitGenerator
+}
+
+// GetModules discovers the modules of the Power Kit from its parameters and returns a typed handle for each of them
+func (k *PowerKit) GetModules(ctx context.Context) (*PowerKitModules, error) {
+	params, err := k.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return k.ModulesFromParameters(params), nil
+}
+
+// ModulesFromParameters returns the typed handles of the modules found in the Power Kit parameters
+func (k *PowerKit) ModulesFromParameters(params map[string]interface{}) *PowerKitModules {
+	modules := &PowerKitModules{All: DecodePowerKitModules(params)}
+	for _, m := range modules.All {
+		switch m.Type {
+		case PowerKitModuleTypeBbcIn:
+			modules.BbcIn = append(modules.BbcIn, k.GetBbcIn(m.Sn))
+		case PowerKitModuleTypeBbcOut:
+			modules.BbcOut = append(modules.BbcOut, k.GetBbcOut(m.Sn))
+		case PowerKitModuleTypeIcLow:
+			modules.IcLow = append(modules.IcLow, k.GetIcLow(m.Sn))
+		case PowerKitModuleTypeBp5000Bp2000:
+			modules.Batteries = append(modules.Batteries, k.GetBattery(m.Sn))
+		case PowerKitModuleTypeLdAc:
+			modules.LdAc = append(modules.LdAc, k.GetLdAc(m.Sn))
+		case PowerKitModuleTypeLdDc:
+			modules.LdDc = append(modules.LdDc, k.GetLdDc(m.Sn))
+		case PowerKitModuleTypeWireless:
+			modules.Wireless = append(modules.Wireless, k.GetWireless(m.Sn))
+		case PowerKitModuleTypeGenerator:
+			modules.Generators = append(modules.Generators, k.GetGenerator(m.Sn))
+		}
+	}
+	return modules
+}
+
+// powerKitModule is the common part of the module handles
+type powerKitModule struct {
+	k          *PowerKit
+	moduleSn   string
+	moduleType PowerKitModuleType
+}
+
+func (m *powerKitModule) GetModuleSn() string {
+	return m.moduleSn
+}
+
+func (m *powerKitModule) GetModuleType() PowerKitModuleType {
+	return m.moduleType
+}
+
+func (m *powerKitModule) setParameter(ctx context.Context, opType string, params map[string]interface{}) (*CmdSetResponse, error) {
+	return m.k.setModuleParameter(ctx, m.moduleSn, opType, m.moduleType, params)
+}
+
+func (k *PowerKit) newModule(moduleSn string, moduleType PowerKitModuleType) powerKitModule {
+	return powerKitModule{k: k, moduleSn: moduleSn, moduleType: moduleType}
+}
+
+// BBC_IN
+
+type PowerKitBbcIn struct {
+	powerKitModule
+}
+
+func (k *PowerKit) GetBbcIn(moduleSn string) *PowerKitBbcIn {
+	return &PowerKitBbcIn{k.newModule(moduleSn, PowerKitModuleTypeBbcIn)}
+}
+
+// SetDcOutputVoltage DC output voltage (0: 12 V, 1: 24 V)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M1093-DCIN-CA7C3", "moduleType": 15362, "operateType": "dischgParaSet", "params": { "volTag": 0 } }
+func (m *PowerKitBbcIn) SetDcOutputVoltage(ctx context.Context, voltage PowerKitDcVoltage) (*CmdSetResponse, error) {
+	if voltage != PowerKitDcVoltage12V && voltage != PowerKitDcVoltage24V {
+		return nil, errors.New("volTag is out of range. Range 0:1")
+	}
+	params := make(map[string]interface{})
+	params["volTag"] = voltage
+	return m.setParameter(ctx, "dischgParaSet", params)
+}
+
+// SetChargingPaused pauses or resumes the charging (chgPause, 0: charging, 1: paused), other charging settings are not changed
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M1093-DCIN-CA7C3", "moduleType": 15362,
+// "operateType": "chgParaSet", "params": { "chgPause": 1, "maxChgCurr": 255, "altVoltLmtEn": 255, "shakeCtrlDisable": 255,
+// "altCableUnit": 255, "altCableLen": -1, "altVoltLmt": 65535 } }
+func (m *PowerKitBbcIn) SetChargingPaused(ctx context.Context, paused bool) (*CmdSetResponse, error) {
+	params := unchangedChargingSettings()
+	params["chgPause"] = boolFlag(paused)
+	return m.setParameter(ctx, "chgParaSet", params)
+}
+
+// SetMaxChargingCurrent maximum charging current, A (range: 1-254), other charging settings are not changed
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M1093-DCIN-CA7C3", "moduleType": 15362,
+// "operateType": "chgParaSet", "params": { "chgPause": 255, "maxChgCurr": 30, "altVoltLmtEn": 255, "shakeCtrlDisable": 255,
+// "altCableUnit": 255, "altCableLen": -1, "altVoltLmt": 65535 } }
+func (m *PowerKitBbcIn) SetMaxChargingCurrent(ctx context.Context, amps int) (*CmdSetResponse, error) {
+	if amps < 1 || amps >= powerKitUnchanged {
+		return nil, errors.New("maxChgCurr is out of range. Range 1:254")
+	}
+	params := unchangedChargingSettings()
+	params["maxChgCurr"] = amps
+	return m.setParameter(ctx, "chgParaSet", params)
+}
+
+// SetAlternatorVoltageLimit enables or disables the alternator voltage limit (altVoltLmtEn) and sets the limit (altVoltLmt, 0.1 V).
+// The limit is not changed if limitDeciVolts is 0
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M1093-DCIN-CA7C3", "moduleType": 15362,
+// "operateType": "chgParaSet", "params": { "chgPause": 255, "maxChgCurr": 255, "altVoltLmtEn": 1, "shakeCtrlDisable": 255,
+// "altCableUnit": 255, "altCableLen": -1, "altVoltLmt": 130 } }
+func (m *PowerKitBbcIn) SetAlternatorVoltageLimit(ctx context.Context, enabled SettingSwitcher, limitDeciVolts int) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("altVoltLmtEn is out of range. Range 0:1")
+	}
+	if limitDeciVolts < 0 || limitDeciVolts >= 65535 {
+		return nil, errors.New("altVoltLmt is out of range. Range 0:65534")
+	}
+	params := unchangedChargingSettings()
+	params["altVoltLmtEn"] = enabled
+	if limitDeciVolts > 0 {
+		params["altVoltLmt"] = limitDeciVolts
+	}
+	return m.setParameter(ctx, "chgParaSet", params)
+}
+
+// SetStartStopDetection enables or disables the engine start/stop (vibration) detection (shakeCtrlDisable, 0: enabled, 1: disabled)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M1093-DCIN-CA7C3", "moduleType": 15362,
+// "operateType": "chgParaSet", "params": { "chgPause": 255, "maxChgCurr": 255, "altVoltLmtEn": 255, "shakeCtrlDisable": 0,
+// "altCableUnit": 255, "altCableLen": -1, "altVoltLmt": 65535 } }
+func (m *PowerKitBbcIn) SetStartStopDetection(ctx context.Context, enabled bool) (*CmdSetResponse, error) {
+	params := unchangedChargingSettings()
+	params["shakeCtrlDisable"] = boolFlag(!enabled)
+	return m.setParameter(ctx, "chgParaSet", params)
+}
+
+type PowerKitCableUnit int
+
+const (
+	PowerKitCableUnitMeter PowerKitCableUnit = 0
+	PowerKitCableUnitFeet  PowerKitCableUnit = 1
+)
+
+// SetAlternatorCable the length of the alternator cable (altCableLen) and its unit (altCableUnit, 0: meters, 1: feet)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M1093-DCIN-CA7C3", "moduleType": 15362,
+// "operateType": "chgParaSet", "params": { "chgPause": 255, "maxChgCurr": 255, "altVoltLmtEn": 255, "shakeCtrlDisable": 255,
+// "altCableUnit": 0, "altCableLen": 3, "altVoltLmt": 65535 } }
+func (m *PowerKitBbcIn) SetAlternatorCable(ctx context.Context, unit PowerKitCableUnit, length int) (*CmdSetResponse, error) {
+	if unit != PowerKitCableUnitMeter && unit != PowerKitCableUnitFeet {
+		return nil, errors.New("altCableUnit is out of range. Range 0:1")
+	}
+	if length <= 0 {
+		return nil, errors.New("altCableLen must be positive")
+	}
+	params := unchangedChargingSettings()
+	params["altCableUnit"] = unit
+	params["altCableLen"] = length
+	return m.setParameter(ctx, "chgParaSet", params)
+}
+
+// unchangedChargingSettings returns chgParaSet parameters that don't change any setting
+func unchangedChargingSettings() map[string]interface{} {
+	params := make(map[string]interface{})
+	params["chgPause"] = powerKitUnchanged
+	params["maxChgCurr"] = powerKitUnchanged
+	params["altVoltLmtEn"] = powerKitUnchanged
+	params["shakeCtrlDisable"] = powerKitUnchanged
+	params["altCableUnit"] = powerKitUnchanged
+	params["altCableLen"] = -1
+	params["altVoltLmt"] = 65535
+	return params
+}
+
+// BBC_OUT
+
+type PowerKitBbcOut struct {
+	powerKitModule
+}
+
+func (k *PowerKit) GetBbcOut(moduleSn string) *PowerKitBbcOut {
+	return &PowerKitBbcOut{k.newModule(moduleSn, PowerKitModuleTypeBbcOut)}
+}
+
+// SetDcOutput enables or disables the DC output (swSta: 0: off 1: on)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M109ZEB4Z0000016", "moduleType": 15363, "operateType": "dischgParaSet", "params": { "swSta": 0 } }
+func (m *PowerKitBbcOut) SetDcOutput(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("swSta is out of range. Range 0:1")
+	}
+	params := make(map[string]interface{})
+	params["swSta"] = enabled
+	return m.setParameter(ctx, "dischgParaSet", params)
+}
+
+// SyncTime synchronizes the RTC time of the Power Kit with t, the time zone is taken from t's location
+// (timeZone: hours, timeZoneQuarter: quarters of an hour of the time zone offset)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M109ZEB4Z0000016", "moduleType": 15363, "operateType": "rtcBroadcast",
+// "params": { "unixTime": 1710835118, "timeZone": 8, "timeZoneQuarter": 1 } }
+func (m *PowerKitBbcOut) SyncTime(ctx context.Context, t time.Time) (*CmdSetResponse, error) {
+	_, offset := t.Zone()
+	params := make(map[string]interface{})
+	params["unixTime"] = t.Unix()
+	params["timeZone"] = offset / 3600
+	params["timeZoneQuarter"] = offset % 3600 / 900
+	return m.setParameter(ctx, "rtcBroadcast", params)
+}
+
+// IC_LOW
+
+type PowerKitIcLow struct {
+	powerKitModule
+}
+
+func (k *PowerKit) GetIcLow(moduleSn string) *PowerKitIcLow {
+	return &PowerKitIcLow{k.newModule(moduleSn, PowerKitModuleTypeIcLow)}
+}
+
+// SetAcOutput AC output, powerOn: 0: AC off, 1: AC on. Other discharging settings are not changed
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M109ZEB4ZE7B0963", "moduleType": 15365, "operateType": "dischgIcParaSet",
+// "params": { "acCurrMaxSet": 255, "powerOn": 0, "acChgDisa": 255, "acFrequencySet": 255, "acVolSet": 255 } }
+func (m *PowerKitIcLow) SetAcOutput(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("powerOn is out of range. Range 0:1")
+	}
+	params := make(map[string]interface{})
+	params["acCurrMaxSet"] = powerKitUnchanged
+	params["powerOn"] = enabled
+	params["acChgDisa"] = powerKitUnchanged
+	params["acFrequencySet"] = powerKitUnchanged
+	params["acVolSet"] = powerKitUnchanged
+	return m.setParameter(ctx, "dischgIcParaSet", params)
+}
+
+// SetAcInputCurrent AC input current, A (range: 1-23)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M109ZEB4ZE7B0963", "moduleType": 15365, "operateType": "dischgIcParaSet", "params": { "acCurrMaxSet": 10 } }
+func (m *PowerKitIcLow) SetAcInputCurrent(ctx context.Context, amps int) (*CmdSetResponse, error) {
+	if amps < 1 || amps > 23 {
+		return nil, errors.New("acCurrMaxSet is out of range. Range 1:23")
+	}
+	params := make(map[string]interface{})
+	params["acCurrMaxSet"] = amps
+	return m.setParameter(ctx, "dischgIcParaSet", params)
+}
+
+// SetGridPowerInPriority Grid power in priority (passByModeEn, 1: on, 2: off), other settings are not changed
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M109ZEB4ZE7B0963", "moduleType": 15365, "operateType": "dsgIcParaSet",
+// "params": { "dsgLowPwrEn": 255, "pfcDsgModeEn": 255, "passByCurrMax": 255, "passByModeEn": 1 } }
+func (m *PowerKitIcLow) SetGridPowerInPriority(ctx context.Context, enabled bool) (*CmdSetResponse, error) {
+	params := make(map[string]interface{})
+	params["dsgLowPwrEn"] = powerKitUnchanged
+	params["pfcDsgModeEn"] = powerKitUnchanged
+	params["passByCurrMax"] = powerKitUnchanged
+	params["passByModeEn"] = 2
+	if enabled {
+		params["passByModeEn"] = 1
+	}
+	return m.setParameter(ctx, "dsgIcParaSet", params)
+}
+
+// BP5000/BP2000
+
+type PowerKitBattery struct {
+	powerKitModule
+}
+
+func (k *PowerKit) GetBattery(moduleSn string) *PowerKitBattery {
+	return &PowerKitBattery{k.newModule(moduleSn, PowerKitModuleTypeBp5000Bp2000)}
+}
+
+// SetChargingUpperLimit Upper limit of charging (range: 50–100)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "0000000000000000", "moduleType": 0, "operateType": "socUpperLimit", "params": { "maxChgSoc": 80 } }
+func (m *PowerKitBattery) SetChargingUpperLimit(ctx context.Context, maxChgSoc int) (*CmdSetResponse, error) {
+	if maxChgSoc < 50 || maxChgSoc > 100 {
+		return nil, errors.New("maxChgSoc is out of range. Range 50:100")
+	}
+	params := make(map[string]interface{})
+	params["maxChgSoc"] = maxChgSoc
+	return m.setParameter(ctx, "socUpperLimit", params)
+}
+
+// SetDischargingLowerLimit Lower limit of discharging (range: 0–50)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "0000000000000000", "moduleType": 0, "operateType": "socLowerLimit", "params": { "minDsgSoc": 40 } }
+func (m *PowerKitBattery) SetDischargingLowerLimit(ctx context.Context, minDsgSoc int) (*CmdSetResponse, error) {
+	if minDsgSoc < 0 || minDsgSoc > 50 {
+		return nil, errors.New("minDsgSoc is out of range. Range 0:50")
+	}
+	params := make(map[string]interface{})
+	params["minDsgSoc"] = minDsgSoc
+	return m.setParameter(ctx, "socLowerLimit", params)
+}
+
+// SetScreenStandByTime screen standby time in minutes (0: never off)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "0000000000000000", "moduleType": 0, "operateType": "lcdStandbyMin", "params": { "minute": 300 } }
+func (m *PowerKitBattery) SetScreenStandByTime(ctx context.Context, minutes int) (*CmdSetResponse, error) {
+	if minutes < 0 {
+		return nil, errors.New("minute must not be negative")
+	}
+	params := make(map[string]interface{})
+	params["minute"] = minutes
+	return m.setParameter(ctx, "lcdStandbyMin", params)
+}
+
+// PowerOff turns the battery off
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "0000000000000000", "moduleType": 0, "operateType": "powerOff", "params": { "enable": 1 } }
+func (m *PowerKitBattery) PowerOff(ctx context.Context) (*CmdSetResponse, error) {
+	params := make(map[string]interface{})
+	params["enable"] = SettingEnabled
+	return m.setParameter(ctx, "powerOff", params)
+}
+
+// SetHeatingByDischarging heating by discharging (0: off, 1: on)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "0000000000000000", "moduleType": 0, "operateType": "ptcDsgCale", "params": { "enable": 1 } }
+func (m *PowerKitBattery) SetHeatingByDischarging(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("enable is out of range. Range 0:1")
+	}
+	params := make(map[string]interface{})
+	params["enable"] = enabled
+	return m.setParameter(ctx, "ptcDsgCale", params)
+}
+
+// ClearChargingErrors clears the charging errors
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "0000000000000000", "moduleType": 0, "operateType": "clearError", "params": { "enable": 1 } }
+func (m *PowerKitBattery) ClearChargingErrors(ctx context.Context) (*CmdSetResponse, error) {
+	params := make(map[string]interface{})
+	params["enable"] = SettingEnabled
+	return m.setParameter(ctx, "clearError", params)
+}
+
+// SetGeneratorAutoStart the battery levels to start (oilStartDownLimit) and stop (oilStopUpLimit) the smart generator,
+// startSoc must be less than stopSoc (range: 0-100). The levels are set by two commands: if the second one fails, the
+// start level is already changed and the error is returned, the call can be repeated
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "0000000000000000", "moduleType": 0, "operateType": "oilStartDownLimit", "params": { "soc": 20 } }
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "0000000000000000", "moduleType": 0, "operateType": "oilStopUpLimit", "params": { "soc": 60 } }
+func (m *PowerKitBattery) SetGeneratorAutoStart(ctx context.Context, startSoc, stopSoc int) (*CmdSetResponse, error) {
+	if startSoc < 0 || stopSoc > 100 || startSoc >= stopSoc {
+		return nil, errors.New("generator start/stop soc is out of range. Range 0:100, start soc must be less than stop soc")
+	}
+	params := make(map[string]interface{})
+	params["soc"] = startSoc
+	start, err := m.setParameter(ctx, "oilStartDownLimit", params)
+	if err = checkCmdResponse(start, err); err != nil {
+		return nil, err
+	}
+	params = make(map[string]interface{})
+	params["soc"] = stopSoc
+	resp, err := m.setParameter(ctx, "oilStopUpLimit", params)
+	return joinDryRun(resp, err, start)
+}
+
+// LD_AC
+
+// PowerKitLdAc is the AC distribution module, the public API doesn't have commands for it
+type PowerKitLdAc struct {
+	powerKitModule
+}
+
+func (k *PowerKit) GetLdAc(moduleSn string) *PowerKitLdAc {
+	return &PowerKitLdAc{k.newModule(moduleSn, PowerKitModuleTypeLdAc)}
+}
+
+// LD_DC
+
+const powerKitLdDcChannels = 6
+
+type PowerKitLdDc struct {
+	powerKitModule
+}
+
+func (k *PowerKit) GetLdDc(moduleSn string) *PowerKitLdDc {
+	return &PowerKitLdDc{k.newModule(moduleSn, PowerKitModuleTypeLdDc)}
+}
+
+// SetChannelRelays the status of the 6-way channel relays, bit n of bitsSwSta is the channel n+1
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M106ZAB4Z000001F", "moduleType": 15368, "operateType": "chSwitch", "params": { "bitsSwSta": 5 } }
+func (m *PowerKitLdDc) SetChannelRelays(ctx context.Context, channels [powerKitLdDcChannels]bool) (*CmdSetResponse, error) {
+	bits := 0
+	for i, on := range channels {
+		if on {
+			bits |= 1 << i
+		}
+	}
+	params := make(map[string]interface{})
+	params["bitsSwSta"] = bits
+	return m.setParameter(ctx, "chSwitch", params)
+}
+
+// Wireless
+
+type PowerKitWireless struct {
+	powerKitModule
+}
+
+func (k *PowerKit) GetWireless(moduleSn string) *PowerKitWireless {
+	return &PowerKitWireless{k.newModule(moduleSn, PowerKitModuleTypeWireless)}
+}
+
+// SetProductName the product name, nameLen is the length of the name in bytes
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M106ZAB4Z000001F", "moduleType": 15370, "operateType": "writeProName", "params": { "nameLen": 4, "name": "test" } }
+func (m *PowerKitWireless) SetProductName(ctx context.Context, name string) (*CmdSetResponse, error) {
+	if name == "" {
+		return nil, errors.New("name must not be empty")
+	}
+	params := make(map[string]interface{})
+	params["nameLen"] = len(name)
+	params["name"] = name
+	return m.setParameter(ctx, "writeProName", params)
+}
+
+// SetScenarios scenarios
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M106ZAB4Z000001F", "moduleType": 15370, "operateType": "setScenes", "params": { "scenes": 3 } }
+func (m *PowerKitWireless) SetScenarios(ctx context.Context, scenes int) (*CmdSetResponse, error) {
+	if scenes < 0 {
+		return nil, errors.New("scenes must not be negative")
+	}
+	params := make(map[string]interface{})
+	params["scenes"] = scenes
+	return m.setParameter(ctx, "setScenes", params)
+}
+
+// TriggerDataReport triggers the comprehensive data report, times is the number of reports
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M106ZAB4Z000001F", "moduleType": 15370, "operateType": "fullIotDataPush", "params": { "times": 1 } }
+func (m *PowerKitWireless) TriggerDataReport(ctx context.Context, times int) (*CmdSetResponse, error) {
+	if times < 1 {
+		return nil, errors.New("times must be positive")
+	}
+	params := make(map[string]interface{})
+	params["times"] = times
+	return m.setParameter(ctx, "fullIotDataPush", params)
+}
+
+// GEN (smart generator)
+
+type PowerKitGenerator struct {
+	powerKitModule
+}
+
+func (k *PowerKit) GetGenerator(moduleSn string) *PowerKitGenerator {
+	return &PowerKitGenerator{k.newModule(moduleSn, PowerKitModuleTypeGenerator)}
+}
+
+// SetRunning starts or stops the smart generator (0: off, 1: on)
+// { "id": 123456789, "version": "1.0", "sn": "M106ZAB4Z000001F", "moduleSn": "M106ZAB4Z000001F", "moduleType": 6402, "operateType": "powerOffGen", "params": { "bitsSwSta": 0 } }
+func (m *PowerKitGenerator) SetRunning(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("bitsSwSta is out of range. Range 0:1")
+	}
+	params := make(map[string]interface{})
+	params["bitsSwSta"] = enabled
+	return m.setParameter(ctx, "powerOffGen", params)
+}
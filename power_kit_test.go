@@ -0,0 +1,76 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPowerKitModules(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{
+		"bp5000": []interface{}{
+			map[string]interface{}{"sn": "M102Z3B4ZEA70077", "soc": 80},
+			map[string]interface{}{"sn": "M102Z3B4ZEA70076", "soc": 75},
+		},
+		"bbcin":           []interface{}{map[string]interface{}{"sn": "M1093-DCIN-CA7C3", "moduleType": 15362}},
+		"iclow":           map[string]interface{}{"moduleSn": "M109ZEB4ZE7B0963"},
+		"lddc.0.sn":       "M1090-LDDC-00001",
+		"onLineModuleCnt": 4,
+		// the keys that start with a module prefix but are not modules
+		"generalInfo": map[string]interface{}{"sn": "M106ZAB4Z000001F"},
+		"bpPowerSoc":  map[string]interface{}{"sn": "M106ZAB4Z000001F"},
+	})
+	kit := client.GetPowerKit("M106ZAB4Z000001F", "")
+	ctx := context.Background()
+
+	modules, err := kit.GetModules(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(modules.All) != 5 || len(modules.Batteries) != 2 || len(modules.BbcIn) != 1 || len(modules.IcLow) != 1 || len(modules.LdDc) != 1 {
+		t.Fatalf("unexpected modules %+v", modules.All)
+	}
+	battery := modules.Batteries[0]
+	if battery.GetModuleSn() != "M102Z3B4ZEA70076" || modules.All[0].Params["soc"] != float64(75) {
+		t.Errorf("unexpected battery %s %v", battery.GetModuleSn(), modules.All[0].Params)
+	}
+
+	if _, err = battery.SetChargingUpperLimit(ctx, 90); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if (*last)["moduleSn"] != "M102Z3B4ZEA70076" || (*last)["moduleType"] != float64(0) || (*last)["operateType"] != "socUpperLimit" {
+		t.Errorf("unexpected request %v", *last)
+	}
+	if _, err = battery.SetChargingUpperLimit(ctx, 40); err == nil {
+		t.Errorf("expected error for maxChgSoc out of range")
+	}
+	if _, err = battery.SetGeneratorAutoStart(ctx, 60, 20); err == nil {
+		t.Errorf("expected error for start soc above stop soc")
+	}
+
+	if _, err = modules.BbcIn[0].SetMaxChargingCurrent(ctx, 30); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	params := (*last)["params"].(map[string]interface{})
+	if params["maxChgCurr"] != float64(30) || params["chgPause"] != float64(powerKitUnchanged) || params["altCableLen"] != float64(-1) {
+		t.Errorf("unexpected params %v", params)
+	}
+
+	if _, err = modules.LdDc[0].SetChannelRelays(ctx, [6]bool{true, false, true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if (*last)["params"].(map[string]interface{})["bitsSwSta"] != float64(5) || (*last)["moduleType"] != float64(PowerKitModuleTypeLdDc) {
+		t.Errorf("unexpected request %v", *last)
+	}
+
+	if _, err = modules.IcLow[0].SetAcInputCurrent(ctx, 24); err == nil {
+		t.Errorf("expected error for acCurrMaxSet out of range")
+	}
+
+	// the moduleSn is sent even if the kit is created without it
+	if _, err = kit.SetDcOutputVoltage(ctx, PowerKitDcVoltage12V); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if moduleSn, ok := (*last)["moduleSn"]; !ok || moduleSn != "" || (*last)["moduleType"] != float64(PowerKitModuleTypeBbcIn) {
+		t.Errorf("unexpected request %v", *last)
+	}
+}
@@ -0,0 +1,258 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Ecoflow documentation: https://developer-eu.ecoflow.com/us/document/powerocean
+// PowerOcean reports the energy flow of the whole installation: the PV strings of the inverter (mpptHeartBeat),
+// the battery packs (bp_addr.<battery sn>), the grid, the house load and the values of each phase (pcsAPhase, pcsBPhase, pcsCPhase).
+// The settings are sent with the "cfg*" parameters (see setCfgParameter)
+
+type PowerOceanWorkMode int
+
+const (
+	PowerOceanWorkModeSelfUse   PowerOceanWorkMode = 0 // self-powered: PV and battery cover the house load first
+	PowerOceanWorkModeTimeOfUse PowerOceanWorkMode = 1 // TOU: the battery is charged and discharged depending on the tariff
+	PowerOceanWorkModeBackup    PowerOceanWorkMode = 2 // the battery is kept charged for the grid outage
+)
+
+const (
+	powerOceanBatteryKey = "bp_addr"
+	powerOceanPvKey      = "mpptPv"
+)
+
+var powerOceanPhaseKeys = [3]string{"pcsAPhase", "pcsBPhase", "pcsCPhase"}
+
+type PowerOcean struct {
+	c  *Client
+	sn string
+}
+
+func (s *PowerOcean) GetSn() string {
+	return s.sn
+}
+
+// PowerOceanPvString is the state of a PV string of the inverter
+type PowerOceanPvString struct {
+	// Index is the number of the string, starting from 0
+	Index   int
+	Voltage float64
+	Current float64
+	Watts   float64
+}
+
+// PowerOceanBattery is the state of a battery pack
+type PowerOceanBattery struct {
+	Sn  string
+	Soc float64
+	// Soh is the battery health, %
+	Soh float64
+	// Watts is the battery power: positive when the pack is charging, negative when it's discharging
+	Watts       float64
+	Voltage     float64
+	Current     float64
+	Temperature float64
+	Cycles      int
+}
+
+// PowerOceanPhase is the state of a grid phase of the inverter
+type PowerOceanPhase struct {
+	Voltage float64
+	Current float64
+	// ActiveWatts (W), ReactiveWatts (var) and ApparentWatts (VA) are the inverter power of the phase
+	ActiveWatts   float64
+	ReactiveWatts float64
+	ApparentWatts float64
+}
+
+// PowerOceanStatus is the energy flow of a PowerOcean installation
+type PowerOceanStatus struct {
+	// Soc is the level of all the battery packs, %
+	Soc float64
+	// SolarWatts is the total power of the PV strings, W
+	SolarWatts float64
+	// BatteryWatts is the total battery power: positive when charging, negative when discharging
+	BatteryWatts float64
+	// GridWatts is the grid power: positive when the power is imported from the grid, negative when it's exported
+	GridWatts float64
+	// HouseLoadWatts is the power consumed by the house
+	HouseLoadWatts float64
+	// InverterWatts is the active power of the inverter (all phases)
+	InverterWatts    float64
+	WorkMode         PowerOceanWorkMode
+	BackupReserveSoc int
+	PvStrings        []PowerOceanPvString
+	// Batteries are sorted by the serial number
+	Batteries []PowerOceanBattery
+	// Phases are the phases A, B and C. Single phase installations report only the phase A
+	Phases [3]PowerOceanPhase
+}
+
+// GridImportWatts returns the power imported from the grid, 0 when the power is exported
+func (s *PowerOceanStatus) GridImportWatts() float64 {
+	return max(s.GridWatts, 0)
+}
+
+// GridExportWatts returns the power exported to the grid, 0 when the power is imported
+func (s *PowerOceanStatus) GridExportWatts() float64 {
+	return max(-s.GridWatts, 0)
+}
+
+// GetStatus returns the typed energy flow of the installation
+func (s *PowerOcean) GetStatus(ctx context.Context) (*PowerOceanStatus, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePowerOceanStatus(params)
+}
+
+// DecodePowerOceanStatus decodes the PowerOcean energy flow from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// Missing parameters have zero values, an error is returned only if the battery level is not found
+func DecodePowerOceanStatus(params map[string]interface{}) (*PowerOceanStatus, error) {
+	soc, ok := NumericValue(params["bpSoc"])
+	if !ok {
+		return nil, errors.New("bpSoc parameter is not found")
+	}
+	value := func(key string) float64 {
+		v, _ := NumericValue(params[key])
+		return v
+	}
+	status := &PowerOceanStatus{
+		Soc:              soc,
+		SolarWatts:       value("mpptPwr"),
+		BatteryWatts:     value("bpPwr"),
+		GridWatts:        value("sysGridPwr"),
+		HouseLoadWatts:   value("sysLoadPwr"),
+		InverterWatts:    value("pcsActPwr"),
+		WorkMode:         PowerOceanWorkMode(value("sysWorkMode")),
+		BackupReserveSoc: int(value("backupReverseSoc")),
+		PvStrings:        decodePowerOceanPvStrings(params),
+		Batteries:        decodePowerOceanBatteries(params),
+	}
+	for i, key := range powerOceanPhaseKeys {
+		phase := objectValue(params, key)
+		status.Phases[i] = PowerOceanPhase{
+			Voltage:       mapFloat(phase, "vol"),
+			Current:       mapFloat(phase, "amp"),
+			ActiveWatts:   mapFloat(phase, "actPwr"),
+			ReactiveWatts: mapFloat(phase, "reactPwr"),
+			ApparentWatts: mapFloat(phase, "apparentPwr"),
+		}
+	}
+	return status, nil
+}
+
+// decodePowerOceanPvStrings decodes the PV strings reported as "mpptHeartBeat": [{"mpptPv": [{...}, {...}]}]
+// or as "mpptPv.N" parameters
+func decodePowerOceanPvStrings(params map[string]interface{}) []PowerOceanPvString {
+	source := params
+	if heartBeats, ok := params["mpptHeartBeat"].([]interface{}); ok && len(heartBeats) > 0 {
+		if m, ok := heartBeats[0].(map[string]interface{}); ok {
+			source = m
+		}
+	}
+	pvs := make([]PowerOceanPvString, 0)
+	for key, m := range keyedObjects(source, powerOceanPvKey) {
+		index, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		pvs = append(pvs, PowerOceanPvString{
+			Index:   index,
+			Voltage: mapFloat(m, "vol"),
+			Current: mapFloat(m, "amp"),
+			Watts:   mapFloat(m, "pwr"),
+		})
+	}
+	sort.Slice(pvs, func(i, j int) bool { return pvs[i].Index < pvs[j].Index })
+	return pvs
+}
+
+// decodePowerOceanBatteries decodes the battery packs reported as "bp_addr.<battery sn>": {...}
+func decodePowerOceanBatteries(params map[string]interface{}) []PowerOceanBattery {
+	batteries := make([]PowerOceanBattery, 0)
+	for sn, m := range keyedObjects(params, powerOceanBatteryKey) {
+		// bp_addr.updateTime is not a battery
+		if _, ok := m["bpSoc"]; !ok {
+			continue
+		}
+		if bpSn, ok := m["bpSn"].(string); ok && bpSn != "" {
+			sn = bpSn
+		}
+		batteries = append(batteries, PowerOceanBattery{
+			Sn:          sn,
+			Soc:         mapFloat(m, "bpSoc"),
+			Soh:         mapFloat(m, "bpSoh"),
+			Watts:       mapFloat(m, "bpPwr"),
+			Voltage:     mapFloat(m, "bpVol"),
+			Current:     mapFloat(m, "bpAmp"),
+			Temperature: mapFloat(m, "bpMaxCellTemp"),
+			Cycles:      int(mapFloat(m, "bpCycles")),
+		})
+	}
+	sort.Slice(batteries, func(i, j int) bool { return batteries[i].Sn < batteries[j].Sn })
+	return batteries
+}
+
+// objectValue returns the object parameter reported either as "key": {...} or flattened as "key.field"
+func objectValue(params map[string]interface{}, key string) map[string]interface{} {
+	object := make(map[string]interface{})
+	if m, ok := params[key].(map[string]interface{}); ok {
+		for field, value := range m {
+			object[field] = value
+		}
+	}
+	for k, value := range params {
+		if field, found := strings.CutPrefix(k, key+"."); found {
+			object[field] = value
+		}
+	}
+	return object
+}
+
+// mapFloat returns the numeric value of the map field, 0 if the field is missing
+func mapFloat(m map[string]interface{}, key string) float64 {
+	v, _ := NumericValue(m[key])
+	return v
+}
+
+// SetBackupReserve Setting the battery level reserved for the grid outage (range 0:100)
+// { "sn":"HJ31ZDH4ZF5H0159", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgBackupReverseSoc":20 } }
+func (s *PowerOcean) SetBackupReserve(ctx context.Context, soc int) (*CmdSetResponse, error) {
+	if soc < 0 || soc > 100 {
+		return nil, errors.New("cfgBackupReverseSoc out of range. Range 0:100")
+	}
+	params := make(map[string]interface{})
+	params["cfgBackupReverseSoc"] = soc
+	return s.setParameter(ctx, params)
+}
+
+// SetWorkMode Setting the work mode (0: self-powered, 1: TOU, 2: backup)
+// { "sn":"HJ31ZDH4ZF5H0159", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":254, "dest":2, "needAck":true, "params":{ "cfgSysWorkMode":0 } }
+func (s *PowerOcean) SetWorkMode(ctx context.Context, mode PowerOceanWorkMode) (*CmdSetResponse, error) {
+	if mode != PowerOceanWorkModeSelfUse && mode != PowerOceanWorkModeTimeOfUse && mode != PowerOceanWorkModeBackup {
+		return nil, fmt.Errorf("unknown work mode %d", mode)
+	}
+	params := make(map[string]interface{})
+	params["cfgSysWorkMode"] = mode
+	return s.setParameter(ctx, params)
+}
+
+func (s *PowerOcean) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
+	return s.c.GetDeviceParameters(ctx, s.sn, params)
+}
+
+func (s *PowerOcean) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
+	return s.c.GetDeviceAllParameters(ctx, s.sn)
+}
+
+func (s *PowerOcean) setParameter(ctx context.Context, params map[string]interface{}) (*CmdSetResponse, error) {
+	return s.c.setCfgParameter(ctx, s.sn, params)
+}
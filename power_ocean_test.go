@@ -0,0 +1,87 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDecodePowerOceanStatus(t *testing.T) {
+	status, err := DecodePowerOceanStatus(map[string]interface{}{
+		"bpSoc":            56.0,
+		"mpptPwr":          3200.0,
+		"bpPwr":            1500.0,
+		"sysGridPwr":       -900.0,
+		"sysLoadPwr":       800.0,
+		"sysWorkMode":      1.0,
+		"backupReverseSoc": 20.0,
+		"mpptHeartBeat": []interface{}{map[string]interface{}{
+			"mpptPv": []interface{}{
+				map[string]interface{}{"vol": 400.0, "amp": 5.0, "pwr": 2000.0},
+				map[string]interface{}{"vol": 300.0, "amp": 4.0, "pwr": 1200.0},
+			},
+		}},
+		"bp_addr.HJ32ZDH4ZF6C0002": map[string]interface{}{"bpSn": "HJ32ZDH4ZF6C0002", "bpSoc": 55.0, "bpPwr": 700.0},
+		"bp_addr.HJ32ZDH4ZF6C0001": map[string]interface{}{"bpSn": "HJ32ZDH4ZF6C0001", "bpSoc": 57.0, "bpPwr": 800.0},
+		"bp_addr.updateTime":       "2024-05-01 10:00:00",
+		"pcsAPhase":                map[string]interface{}{"vol": 231.0, "amp": 3.5, "actPwr": 800.0},
+		"pcsBPhase.vol":            229.0,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Soc != 56 || status.WorkMode != PowerOceanWorkModeTimeOfUse || status.BackupReserveSoc != 20 {
+		t.Errorf("unexpected status %+v", *status)
+	}
+	if status.GridImportWatts() != 0 || status.GridExportWatts() != 900 {
+		t.Errorf("unexpected grid import/export %v/%v", status.GridImportWatts(), status.GridExportWatts())
+	}
+	if len(status.PvStrings) != 2 || status.PvStrings[1] != (PowerOceanPvString{Index: 1, Voltage: 300, Current: 4, Watts: 1200}) {
+		t.Errorf("unexpected PV strings %+v", status.PvStrings)
+	}
+	if len(status.Batteries) != 2 || status.Batteries[0].Sn != "HJ32ZDH4ZF6C0001" || status.Batteries[0].Watts != 800 {
+		t.Errorf("unexpected batteries %+v", status.Batteries)
+	}
+	if status.Phases[0].Voltage != 231 || status.Phases[0].ActiveWatts != 800 || status.Phases[1].Voltage != 229 {
+		t.Errorf("unexpected phases %+v", status.Phases)
+	}
+
+	if _, err = DecodePowerOceanStatus(map[string]interface{}{}); err == nil {
+		t.Errorf("expected error for missing bpSoc")
+	}
+}
+
+func TestPowerOceanSetters(t *testing.T) {
+	client, last := newTestClient(t, nil)
+	po := client.GetPowerOcean("HJ31ZDH4ZF5H0159")
+
+	runSetterTests(t, last, []setterTest{
+		{
+			name: "Work mode",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return po.SetWorkMode(ctx, PowerOceanWorkModeBackup)
+			},
+			params: map[string]interface{}{"cfgSysWorkMode": float64(2)},
+		},
+		{
+			name:   "Backup reserve",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return po.SetBackupReserve(ctx, 30) },
+			params: map[string]interface{}{"cfgBackupReverseSoc": float64(30)},
+		},
+		{
+			name: "Backup reserve out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return po.SetBackupReserve(ctx, 101) },
+		},
+		{
+			name: "Unknown work mode",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return po.SetWorkMode(ctx, 5) },
+		},
+	})
+
+	// the settings are sent with the cfg header
+	if _, err := po.SetBackupReserve(context.Background(), 20); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if (*last)["cmdId"] != float64(17) {
+		t.Errorf("unexpected request %v", *last)
+	}
+}
@@ -2,10 +2,7 @@ package ecoflow
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"time"
 )
 
 // Ecoflow documentation:
@@ -236,24 +233,10 @@ func (s *PowerStation) GetAllParameters(ctx context.Context) (map[string]interfa
 }
 
 func (s *PowerStation) setParameter(ctx context.Context, opType string, modType ModuleType, params map[string]interface{}) (*CmdSetResponse, error) {
-	cmdReq := CmdSetRequest{
-		Id:          fmt.Sprint(time.Now().UnixMilli()),
+	return s.c.SendCommand(ctx, Command{
+		Sn:          s.sn,
 		OperateType: opType,
 		ModuleType:  modType,
-		Sn:          s.sn,
 		Params:      params,
-	}
-
-	jsonData, err := json.Marshal(cmdReq)
-	if err != nil {
-		return nil, err
-	}
-
-	var req map[string]interface{}
-
-	err = json.Unmarshal(jsonData, &req)
-	if err != nil {
-		return nil, err
-	}
-	return s.c.SetDeviceParameter(ctx, req)
+	})
 }
@@ -0,0 +1,57 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPowerStation3(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{
+		"bmsBattSoc":    91,
+		"powGetAcOut":   35,
+		"flowInfoAcOut": 2,
+		"flowInfo12v":   0,
+		"cmsMaxChgSoc":  100,
+	})
+	ps := client.GetPowerStation3("R651ZEB5XH000001")
+	ctx := context.Background()
+
+	status, err := ps.GetStatus(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := PowerStation3Status{Soc: 91, AcOutputWatts: 35, AcOutputEnabled: true, MaxChargeSoc: 100}
+	if *status != expected {
+		t.Errorf("got %+v, expected %+v", *status, expected)
+	}
+
+	if _, err = ps.SetAcOutput(ctx, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if (*last)["cmdId"] != float64(17) || (*last)["params"].(map[string]interface{})["cfgAcOutOpen"] != false {
+		t.Errorf("unexpected request %v", *last)
+	}
+	if _, err = ps.SetAcChargingWatts(ctx, 400); err == nil {
+		t.Errorf("expected error for cfgPlugInInfoAcInChgPowMax out of range")
+	}
+}
+
+func TestPowerStation3AcChargingRange(t *testing.T) {
+	client, _ := newTestClient(t, map[string]interface{}{})
+	tests := []struct {
+		sn  string
+		max int
+	}{
+		{"R651ZEB5XH000001", 305},
+		{"P351ZAHAPH000001", 1500},
+		{"XXXX000000000001", 2400},
+	}
+	for _, tt := range tests {
+		if _, maxWatts := client.GetPowerStation3(tt.sn).AcChargingRange(); maxWatts != tt.max {
+			t.Errorf("%s: got max %d, expected %d", tt.sn, maxWatts, tt.max)
+		}
+	}
+	if _, err := client.GetPowerStation3("XXXX000000000001").SetAcChargingWatts(context.Background(), 2000); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
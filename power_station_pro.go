@@ -2,10 +2,8 @@ package ecoflow
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"time"
 )
 
 // Ecoflow documentation: https://developer-eu.ecoflow.com/us/document/deltapro
@@ -20,17 +18,205 @@ func (s *PowerStationPro) GetSn() string {
 	return s.sn
 }
 
-// SetXboostSwitcher Setting the X-Boost switch
-// "params":{ "cmdSet": 32, "id": 66, "enabled": 0, "xboost": 0 }
+// PowerStationProStatus is the state of a Delta Pro decoded from "pd.*", "ems.*", "inv.*", "mppt.*" and "bmsMaster.*" parameters
+type PowerStationProStatus struct {
+	// Soc is the battery level, %
+	Soc float64
+	// InputWatts and OutputWatts are the total input and output power, W
+	InputWatts  float64
+	OutputWatts float64
+	// RemainingMinutes is the remaining charging or discharging time
+	RemainingMinutes int
+	AcInputWatts     float64
+	AcOutputWatts    float64
+	SolarInputWatts  float64
+	CarOutputWatts   float64
+	AcEnabled        bool
+	XboostEnabled    bool
+	AcFrequency      GridFrequency
+	// AcChargingWatts is the AC charging power setting, W
+	AcChargingWatts   int
+	DcOutputEnabled   bool
+	CarChargerEnabled bool
+	MaxChargeSoc      int
+	MinDischargeSoc   int
+	// BackupReserveEnabled and BackupReserveSoc are the energy management settings
+	BackupReserveEnabled bool
+	BackupReserveSoc     int
+	// BatteryTemperature is the main battery temperature, ℃
+	BatteryTemperature float64
+	// BatteryVoltage is the main battery voltage, V
+	BatteryVoltage float64
+}
+
+// GetStatus returns the typed state of the power station
+func (s *PowerStationPro) GetStatus(ctx context.Context) (*PowerStationProStatus, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePowerStationProStatus(params)
+}
+
+// DecodePowerStationProStatus decodes the Delta Pro state from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// Missing parameters have zero values, an error is returned only if the battery level is not found
+func DecodePowerStationProStatus(params map[string]interface{}) (*PowerStationProStatus, error) {
+	soc, ok := NumericValue(params["pd.soc"])
+	if !ok {
+		return nil, errors.New("pd.soc parameter is not found")
+	}
+	value := func(key string) float64 {
+		v, _ := NumericValue(params[key])
+		return v
+	}
+	return &PowerStationProStatus{
+		Soc:                  soc,
+		InputWatts:           value("pd.wattsInSum"),
+		OutputWatts:          value("pd.wattsOutSum"),
+		RemainingMinutes:     int(value("pd.remainTime")),
+		AcInputWatts:         value("inv.inputWatts"),
+		AcOutputWatts:        value("inv.outputWatts"),
+		SolarInputWatts:      value("mppt.inWatts"),
+		CarOutputWatts:       value("mppt.carOutWatts"),
+		AcEnabled:            value("inv.cfgAcEnabled") == 1,
+		XboostEnabled:        value("inv.cfgAcXboost") == 1,
+		AcFrequency:          GridFrequency(value("inv.cfgAcOutFreq")),
+		AcChargingWatts:      int(value("inv.cfgSlowChgWatts")),
+		DcOutputEnabled:      value("pd.dcOutState") == 1,
+		CarChargerEnabled:    value("mppt.carState") == 1,
+		MaxChargeSoc:         int(value("ems.maxChargeSoc")),
+		MinDischargeSoc:      int(value("ems.minDsgSoc")),
+		BackupReserveEnabled: value("pd.watthisconfig") == 1,
+		BackupReserveSoc:     int(value("pd.bpPowerSoc")),
+		BatteryTemperature:   value("bmsMaster.temp"),
+		BatteryVoltage:       value("bmsMaster.vol") / 1000,
+	}, nil
+}
+
+// SetAcDischarge Setting the AC output (enabled) and the X-Boost (xboost) switches, the command carries both of them
+// "params":{ "cmdSet": 32, "id": 66, "enabled": 1, "xboost": 0 }
+func (s *PowerStationPro) SetAcDischarge(ctx context.Context, enabled, xboost SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled || xboost != SettingEnabled && xboost != SettingDisabled {
+		return nil, errors.New("enabled and xboost out of range. Range 0:1")
+	}
+	params := make(map[string]interface{})
+	params["cmdSet"] = 32
+	params["id"] = 66
+	params["enabled"] = enabled
+	params["xboost"] = xboost
+	return s.setParameter(ctx, params)
+}
+
+// SetAcOutput Setting the AC output switch, the current X-Boost state is read from the device and sent unchanged
+// "params":{ "cmdSet": 32, "id": 66, "enabled": 1, "xboost": 0 }
+func (s *PowerStationPro) SetAcOutput(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("enabled out of range. Range 0:1")
+	}
+	current, err := s.currentSettings(ctx, "inv.cfgAcXboost")
+	if err != nil {
+		return nil, err
+	}
+	return s.SetAcDischarge(ctx, enabled, SettingSwitcher(boolFlag(current[0] == 1)))
+}
+
+// SetXboostSwitcher Setting the X-Boost switch, the current AC output state is read from the device and sent unchanged
+// "params":{ "cmdSet": 32, "id": 66, "enabled": 1, "xboost": 0 }
 func (s *PowerStationPro) SetXboostSwitcher(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("xboost out of range. Range 0:1")
+	}
+	current, err := s.currentSettings(ctx, "inv.cfgAcEnabled")
+	if err != nil {
+		return nil, err
+	}
+	return s.SetAcDischarge(ctx, SettingSwitcher(boolFlag(current[0] == 1)), enabled)
+}
+
+// SetAcOutputFrequency Setting the AC output frequency (1: 50 Hz, 2: 60 Hz). The command also carries the AC output and
+// X-Boost switches, the current states are read from the device and sent unchanged
+// "params":{ "cmdSet": 32, "id": 66, "enabled": 1, "xboost": 0, "out_freq": 1 }
+func (s *PowerStationPro) SetAcOutputFrequency(ctx context.Context, outFreq GridFrequency) (*CmdSetResponse, error) {
+	if outFreq != GridFrequency50Hz && outFreq != GridFrequency60Hz {
+		return nil, errors.New("out_freq out of range. Range 1:2")
+	}
+	current, err := s.currentSettings(ctx, "inv.cfgAcEnabled", "inv.cfgAcXboost")
+	if err != nil {
+		return nil, err
+	}
 	params := make(map[string]interface{})
 	params["cmdSet"] = 32
 	params["id"] = 66
+	params["enabled"] = boolFlag(current[0] == 1)
+	params["xboost"] = boolFlag(current[1] == 1)
+	params["out_freq"] = outFreq
+	return s.setParameter(ctx, params)
+}
+
+// SetDcOutput Setting the DC (USB) output switch
+// "params":{ "cmdSet": 32, "id": 34, "enabled": 1 }
+func (s *PowerStationPro) SetDcOutput(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("enabled out of range. Range 0:1")
+	}
+	params := make(map[string]interface{})
+	params["cmdSet"] = 32
+	params["id"] = 34
 	params["enabled"] = enabled
-	params["xboost"] = enabled
 	return s.setParameter(ctx, params)
 }
 
+// SetBackupReserve Energy management: the backup reserve switch and level (bpPowerSoc, range 5:100).
+// The battery isn't discharged below the backup reserve level while the grid power is available.
+// The command also carries the discharge and charge limits, the current limits are read from the device and sent unchanged.
+// An error is returned if the limits are not reported or out of range (minDsgSoc 0:30, maxChgSoc 50:100)
+// "params":{ "cmdSet": 32, "id": 94, "isConfig": 1, "bpPowerSoc": 50, "minDsgSoc": 10, "maxChgSoc": 100 }
+func (s *PowerStationPro) SetBackupReserve(ctx context.Context, enabled SettingSwitcher, bpPowerSoc int) (*CmdSetResponse, error) {
+	if enabled != SettingEnabled && enabled != SettingDisabled {
+		return nil, errors.New("isConfig out of range. Range 0:1")
+	}
+	if bpPowerSoc < 5 || bpPowerSoc > 100 {
+		return nil, errors.New("bpPowerSoc out of range. Range 5:100")
+	}
+	current, err := s.currentSettings(ctx, "ems.minDsgSoc", "ems.maxChargeSoc")
+	if err != nil {
+		return nil, err
+	}
+	minDsgSoc, maxChgSoc := current[0], current[1]
+	if minDsgSoc < 0 || minDsgSoc > 30 {
+		return nil, fmt.Errorf("current ems.minDsgSoc %d out of range. Range 0:30", minDsgSoc)
+	}
+	if maxChgSoc < 50 || maxChgSoc > 100 {
+		return nil, fmt.Errorf("current ems.maxChargeSoc %d out of range. Range 50:100", maxChgSoc)
+	}
+	params := make(map[string]interface{})
+	params["cmdSet"] = 32
+	params["id"] = 94
+	params["isConfig"] = enabled
+	params["bpPowerSoc"] = bpPowerSoc
+	params["minDsgSoc"] = minDsgSoc
+	params["maxChgSoc"] = maxChgSoc
+	return s.setParameter(ctx, params)
+}
+
+// currentSettings reads the parameters that are sent unchanged with a combined command. Unlike GetStatus it returns
+// an error if a parameter is not reported, so a missing value is never sent as 0
+func (s *PowerStationPro) currentSettings(ctx context.Context, keys ...string) ([]int, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]int, len(keys))
+	for i, key := range keys {
+		v, ok := mapInt(params, key)
+		if !ok {
+			return nil, fmt.Errorf("%s parameter is not found", key)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
 // SetCarChargerSwitch Setting the car charger switch
 // "params":{ "cmdSet": 32, "id": 81, "enabled": 1 }
 func (s *PowerStationPro) SetCarChargerSwitch(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
@@ -67,9 +253,12 @@ func (s *PowerStationPro) SetMinDischargeLevel(ctx context.Context, minDsgSoc in
 	return s.setParameter(ctx, params)
 }
 
-// SetCarInputCurrent Setting the car input current
+// SetCarInputCurrent Setting the car input current, mA (range 4000:8000)
 // "params":{ "cmdSet": 32, "id": 71, "currMa": 4000 }
 func (s *PowerStationPro) SetCarInputCurrent(ctx context.Context, currMa int) (*CmdSetResponse, error) {
+	if currMa < 4000 || currMa > 8000 {
+		return nil, errors.New("currMa out of range. Range 4000:8000")
+	}
 	params := make(map[string]interface{})
 	params["cmdSet"] = 32
 	params["id"] = 71
@@ -90,6 +279,9 @@ func (s *PowerStationPro) SetBeepSwitch(ctx context.Context, enabled SettingSwit
 // SetScreenBrightness Setting the screen brightness
 // "params":{ "cmdSet": 32, "id": 39, "lcdBrightness": 100 }
 func (s *PowerStationPro) SetScreenBrightness(ctx context.Context, lcdBrightness int) (*CmdSetResponse, error) {
+	if lcdBrightness < 0 || lcdBrightness > 100 {
+		return nil, errors.New("lcdBrightness out of range. Range 0:100")
+	}
 	params := make(map[string]interface{})
 	params["cmdSet"] = 32
 	params["id"] = 39
@@ -153,9 +345,12 @@ func (s *PowerStationPro) SetAcStandByTime(ctx context.Context, standByMins int)
 	return s.setParameter(ctx, params)
 }
 
-// SetAcChargingSettings AC charging settings
-// "params":{ "cmdSet": 32, "id": 69, "slowChgPower": 0 }
+// SetAcChargingSettings AC charging settings, W (range 200:2900)
+// "params":{ "cmdSet": 32, "id": 69, "slowChgPower": 1500 }
 func (s *PowerStationPro) SetAcChargingSettings(ctx context.Context, slowChgPower int) (*CmdSetResponse, error) {
+	if slowChgPower < 200 || slowChgPower > 2900 {
+		return nil, errors.New("slowChgPower out of range. Range 200:2900")
+	}
 	params := make(map[string]interface{})
 	params["cmdSet"] = 32
 	params["id"] = 69
@@ -166,6 +361,9 @@ func (s *PowerStationPro) SetAcChargingSettings(ctx context.Context, slowChgPowe
 // SetPvChargingType PV charging type
 // "params":{ "cmdSet": 32, "id": 82, "chgType": 0 }
 func (s *PowerStationPro) SetPvChargingType(ctx context.Context, chgType PowerStationPvChargeType) (*CmdSetResponse, error) {
+	if chgType < PowerStationPvChargeTypeAuto || chgType > PowerStationPvChargeTypeAdapter {
+		return nil, errors.New("chgType out of range. Range 0:2")
+	}
 	params := make(map[string]interface{})
 	params["cmdSet"] = 32
 	params["id"] = 82
@@ -192,22 +390,8 @@ func (s *PowerStationPro) GetAllParameters(ctx context.Context) (map[string]inte
 }
 
 func (s *PowerStationPro) setParameter(ctx context.Context, params map[string]interface{}) (*CmdSetResponse, error) {
-	cmdReq := CmdSetRequest{
-		Id:     fmt.Sprint(time.Now().UnixMilli()),
+	return s.c.SendCommand(ctx, Command{
 		Sn:     s.sn,
 		Params: params,
-	}
-
-	jsonData, err := json.Marshal(cmdReq)
-	if err != nil {
-		return nil, err
-	}
-
-	var req map[string]interface{}
-
-	err = json.Unmarshal(jsonData, &req)
-	if err != nil {
-		return nil, err
-	}
-	return s.c.SetDeviceParameter(ctx, req)
+	})
 }
@@ -0,0 +1,124 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+var powerStationProQuota = map[string]interface{}{
+	"pd.soc":           82,
+	"pd.wattsInSum":    400,
+	"pd.wattsOutSum":   120,
+	"inv.cfgAcEnabled": 1,
+	"inv.cfgAcXboost":  0,
+	"inv.cfgAcOutFreq": 1,
+	"pd.watthisconfig": 1,
+	"pd.bpPowerSoc":    40,
+	"ems.minDsgSoc":    10,
+	"ems.maxChargeSoc": 95,
+	"bmsMaster.vol":    50123,
+}
+
+func TestPowerStationProStatus(t *testing.T) {
+	client, _ := newTestClient(t, powerStationProQuota)
+	status, err := client.GetPowerStationPro("DCABZ8ZE4000001").GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := PowerStationProStatus{Soc: 82, InputWatts: 400, OutputWatts: 120, AcEnabled: true, AcFrequency: GridFrequency50Hz,
+		MaxChargeSoc: 95, MinDischargeSoc: 10, BackupReserveEnabled: true, BackupReserveSoc: 40, BatteryVoltage: 50.123}
+	if *status != expected {
+		t.Errorf("got %+v, expected %+v", *status, expected)
+	}
+}
+
+func TestPowerStationProSetters(t *testing.T) {
+	client, last := newTestClient(t, powerStationProQuota)
+	ps := client.GetPowerStationPro("DCABZ8ZE4000001")
+
+	runSetterTests(t, last, []setterTest{
+		{
+			// the current AC output state is sent with the X-Boost switch
+			name:   "X-Boost",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetXboostSwitcher(ctx, SettingEnabled) },
+			params: map[string]interface{}{"cmdSet": float64(32), "id": float64(66), "enabled": float64(1), "xboost": float64(1)},
+		},
+		{
+			name:   "AC output",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetAcOutput(ctx, SettingDisabled) },
+			params: map[string]interface{}{"cmdSet": float64(32), "id": float64(66), "enabled": float64(0), "xboost": float64(0)},
+		},
+		{
+			// the AC output and X-Boost switches are sent with the frequency
+			name: "AC output frequency",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return ps.SetAcOutputFrequency(ctx, GridFrequency60Hz)
+			},
+			params: map[string]interface{}{"cmdSet": float64(32), "id": float64(66), "enabled": float64(1), "xboost": float64(0), "out_freq": float64(2)},
+		},
+		{
+			name: "Backup reserve",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return ps.SetBackupReserve(ctx, SettingEnabled, 60)
+			},
+			params: map[string]interface{}{"cmdSet": float64(32), "id": float64(94), "isConfig": float64(1), "bpPowerSoc": float64(60),
+				"minDsgSoc": float64(10), "maxChgSoc": float64(95)},
+		},
+		{
+			name: "Backup reserve out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return ps.SetBackupReserve(ctx, SettingEnabled, 101)
+			},
+		},
+		{
+			name: "AC output frequency out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetAcOutputFrequency(ctx, 3) },
+		},
+		{
+			name: "AC output out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetAcOutput(ctx, 2) },
+		},
+	})
+}
+
+// TestPowerStationProMissingSettings checks that the combined commands are not sent if the settings that must be kept
+// unchanged are not reported
+func TestPowerStationProMissingSettings(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{"pd.soc": 82, "ems.minDsgSoc": 10, "ems.maxChargeSoc": 20})
+	ps := client.GetPowerStationPro("DCABZ8ZE4000001")
+
+	runSetterTests(t, last, []setterTest{
+		{
+			name: "AC output without X-Boost state",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetAcOutput(ctx, SettingEnabled) },
+		},
+		{
+			name: "X-Boost without AC output state",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return ps.SetXboostSwitcher(ctx, SettingEnabled) },
+		},
+		{
+			name: "AC output frequency without switches",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return ps.SetAcOutputFrequency(ctx, GridFrequency50Hz)
+			},
+		},
+		{
+			// ems.maxChargeSoc is reported, but it's out of 50:100 range
+			name: "Backup reserve with invalid charge limit",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return ps.SetBackupReserve(ctx, SettingEnabled, 50)
+			},
+		},
+	})
+
+	client, last = newTestClient(t, map[string]interface{}{"pd.soc": 82, "ems.minDsgSoc": 10})
+	ps = client.GetPowerStationPro("DCABZ8ZE4000001")
+	runSetterTests(t, last, []setterTest{
+		{
+			name: "Backup reserve without charge limit",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return ps.SetBackupReserve(ctx, SettingEnabled, 50)
+			},
+		},
+	})
+}
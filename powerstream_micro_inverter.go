@@ -2,15 +2,33 @@ package ecoflow
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"time"
+	"math"
 )
 
 // Ecoflow documentation:
 // https://developer-eu.ecoflow.com/us/document/powerStreamMicroInverter
 
+// PowerStreamStatus is the state of a PowerStream micro inverter decoded from "20_1.*" parameters.
+// The device reports watts and volts in 0.1 units, they are converted to W and V
+type PowerStreamStatus struct {
+	Pv1InputWatts float64
+	Pv1InputVolt  float64
+	Pv2InputWatts float64
+	Pv2InputVolt  float64
+	// BatterySoc is the battery level, %
+	BatterySoc float64
+	// BatteryWatts is the battery power, positive when the battery is charging
+	BatteryWatts        float64
+	InverterOutputWatts float64
+	GridVoltage         float64
+	// PermanentWatts is the custom load power (see SetCustomLoadPowerWatts), W
+	PermanentWatts float64
+	SupplyPriority int
+	LowerLimit     int
+	UpperLimit     int
+}
+
 type PowerStreamMicroInverter struct {
 	c  *Client
 	sn string
@@ -28,17 +46,31 @@ func (s *PowerStreamMicroInverter) SetPowerSupplyPriority(ctx context.Context, s
 	return s.setParameter(ctx, "WN511_SET_SUPPLY_PRIORITY_PACK", params)
 }
 
-// SetCustomLoadPowerSettings Custom load power settings(Range: 0 W–600 W; unit: 0.1 W)
+// SetCustomLoadPowerSettings Custom load power settings(Range: 0 W–600 W; unit: 0.1 W, i.e. 0:6000)
 // {"sn": "HW513000SF767194","cmdCode": "WN511_SET_PERMANENT_WATTS_PACK","params": {"permanentWatts": 20}}
+//
+// Deprecated: permanentWatts is sent as is, in 0.1 W. Use SetCustomLoadPowerWatts to set the power in W
 func (s *PowerStreamMicroInverter) SetCustomLoadPowerSettings(ctx context.Context, permanentWatts float64) (*CmdSetResponse, error) {
-	if permanentWatts < 0 || permanentWatts > 600 {
-		return nil, errors.New("permanentWatts is out of range. Range 0:600, unit 0.1W")
+	if permanentWatts < 0 || permanentWatts > 6000 {
+		return nil, errors.New("permanentWatts is out of range. Range 0:6000, unit 0.1W (use SetCustomLoadPowerWatts to set the power in W)")
 	}
 	params := make(map[string]interface{})
 	params["permanentWatts"] = permanentWatts
 	return s.setParameter(ctx, "WN511_SET_PERMANENT_WATTS_PACK", params)
 }
 
+// SetCustomLoadPowerWatts Custom load power settings(Range: 0 W–600 W)
+// The device expects the value in 0.1 W, so watts is multiplied by 10, e.g. 2 W is sent as 20:
+// {"sn": "HW513000SF767194","cmdCode": "WN511_SET_PERMANENT_WATTS_PACK","params": {"permanentWatts": 20}}
+func (s *PowerStreamMicroInverter) SetCustomLoadPowerWatts(ctx context.Context, watts float64) (*CmdSetResponse, error) {
+	if watts < 0 || watts > 600 {
+		return nil, errors.New("permanentWatts is out of range. Range 0:600, unit W")
+	}
+	params := make(map[string]interface{})
+	params["permanentWatts"] = int(math.Round(watts * 10))
+	return s.setParameter(ctx, "WN511_SET_PERMANENT_WATTS_PACK", params)
+}
+
 // SetLowerLimitSettingsForBatterDischarging Lower limit settings for battery discharging(lowerLimit: 1-30)
 // {"sn": "HW513000SF767194","cmdCode": "WN511_SET_BAT_LOWER_PACK","params": {"lowerLimit": 20}}
 func (s *PowerStreamMicroInverter) SetLowerLimitSettingsForBatterDischarging(ctx context.Context, lowerLimit float64) (*CmdSetResponse, error) {
@@ -83,6 +115,15 @@ func (s *PowerStreamMicroInverter) DeleteScheduledSwitchingTasks(ctx context.Con
 	return s.setParameter(ctx, "WN511_DELETE_TIME_TASK", params)
 }
 
+// GetScheduledSwitchingTasks returns the scheduled tasks ordered by index
+func (s *PowerStreamMicroInverter) GetScheduledSwitchingTasks(ctx context.Context) ([]PlugTask, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePlugTasks(params), nil
+}
+
 func (s *PowerStreamMicroInverter) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
 	return s.c.GetDeviceParameters(ctx, s.sn, params)
 }
@@ -91,24 +132,44 @@ func (s *PowerStreamMicroInverter) GetAllParameters(ctx context.Context) (map[st
 	return s.c.GetDeviceAllParameters(ctx, s.sn)
 }
 
-func (s *PowerStreamMicroInverter) setParameter(ctx context.Context, cmdCode string, params map[string]interface{}) (*CmdSetResponse, error) {
-	cmdReq := CmdSetRequest{
-		Id:      fmt.Sprint(time.Now().UnixMilli()),
-		CmdCode: cmdCode,
-		Sn:      s.sn,
-		Params:  params,
-	}
-
-	jsonData, err := json.Marshal(cmdReq)
+// GetStatus returns the current state of the inverter
+func (s *PowerStreamMicroInverter) GetStatus(ctx context.Context) (*PowerStreamStatus, error) {
+	params, err := s.GetAllParameters(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return DecodePowerStreamStatus(params)
+}
 
-	var req map[string]interface{}
-
-	err = json.Unmarshal(jsonData, &req)
-	if err != nil {
-		return nil, err
+// DecodePowerStreamStatus decodes the inverter state from the parameters (see GetAllParameters or DeviceSnapshot.Params)
+func DecodePowerStreamStatus(params map[string]interface{}) (*PowerStreamStatus, error) {
+	if _, ok := params["20_1.invOutputWatts"]; !ok {
+		return nil, errors.New("20_1.invOutputWatts parameter is not found")
 	}
-	return s.c.SetDeviceParameter(ctx, req)
+	value := func(key string) float64 {
+		v, _ := NumericValue(params["20_1."+key])
+		return v
+	}
+	return &PowerStreamStatus{
+		Pv1InputWatts:       value("pv1InputWatts") / 10,
+		Pv1InputVolt:        value("pv1InputVolt") / 10,
+		Pv2InputWatts:       value("pv2InputWatts") / 10,
+		Pv2InputVolt:        value("pv2InputVolt") / 10,
+		BatterySoc:          value("batSoc"),
+		BatteryWatts:        value("batInputWatts") / 10,
+		InverterOutputWatts: value("invOutputWatts") / 10,
+		GridVoltage:         value("invOpVolt") / 10,
+		PermanentWatts:      value("permanentWatts") / 10,
+		SupplyPriority:      int(value("supplyPriority")),
+		LowerLimit:          int(value("lowerLimit")),
+		UpperLimit:          int(value("upperLimit")),
+	}, nil
+}
+
+func (s *PowerStreamMicroInverter) setParameter(ctx context.Context, cmdCode string, params map[string]interface{}) (*CmdSetResponse, error) {
+	return s.c.SendCommand(ctx, Command{
+		Sn:      s.sn,
+		CmdCode: cmdCode,
+		Params:  params,
+	})
 }
@@ -0,0 +1,53 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPowerStreamMicroInverter(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{
+		"20_1.pv1InputWatts":  1523,
+		"20_1.invOutputWatts": 1450,
+		"20_1.invOpVolt":      2301,
+		"20_1.batSoc":         76,
+		"20_1.permanentWatts": 1000,
+	})
+	inv := client.GetPowerStreamMicroInverter("HW513000SF767194")
+	ctx := context.Background()
+
+	status, err := inv.GetStatus(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := PowerStreamStatus{Pv1InputWatts: 152.3, InverterOutputWatts: 145, GridVoltage: 230.1, BatterySoc: 76, PermanentWatts: 100}
+	if *status != expected {
+		t.Errorf("got %+v, expected %+v", *status, expected)
+	}
+
+	// the custom load power is set in W and sent in 0.1 W
+	if _, err = inv.SetCustomLoadPowerWatts(ctx, 250.5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := (*last)["params"].(map[string]interface{})["permanentWatts"]; v != float64(2505) {
+		t.Errorf("expected permanentWatts 2505, got %v", v)
+	}
+	if _, err = inv.SetCustomLoadPowerWatts(ctx, 601); err == nil {
+		t.Errorf("expected error for custom load power out of range")
+	}
+
+	// the deprecated setter sends the value as is, in 0.1 W
+	if _, err = inv.SetCustomLoadPowerSettings(ctx, 200); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := (*last)["params"].(map[string]interface{})["permanentWatts"]; v != float64(200) {
+		t.Errorf("expected permanentWatts 200, got %v", v)
+	}
+	// 600 W in 0.1 W
+	if _, err = inv.SetCustomLoadPowerSettings(ctx, 6000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err = inv.SetCustomLoadPowerSettings(ctx, 6001); err == nil {
+		t.Errorf("expected error for custom load power out of range")
+	}
+}
@@ -0,0 +1,113 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+)
+
+// Smart Generator (Dual Fuel) can be controlled indirectly by a power station (see PowerStation.SetSoCToTurnOnSmartGenerator)
+// or by a Power Kit (see PowerKit.SetOilPocketStart). SmartGenerator controls the generator directly.
+//
+// Experimental: the Ecoflow Open API documentation doesn't describe the Smart Generator. The status is decoded from the
+// pd.* parameters reported by the generator, they aren't confirmed by a published spec, check them with GetAllParameters.
+// There are no set commands (the engine start and stop in particular) until the commands are documented: use GenericDevice
+// with a schema that describes the commands of your generator
+
+type SmartGeneratorFuelType int
+
+const (
+	SmartGeneratorFuelTypeGasoline SmartGeneratorFuelType = 1
+	SmartGeneratorFuelTypeLpg      SmartGeneratorFuelType = 2
+)
+
+type SmartGeneratorEngineState int
+
+const (
+	SmartGeneratorEngineStopped  SmartGeneratorEngineState = 0
+	SmartGeneratorEngineStarting SmartGeneratorEngineState = 1
+	SmartGeneratorEngineRunning  SmartGeneratorEngineState = 2
+	SmartGeneratorEngineStopping SmartGeneratorEngineState = 3
+)
+
+type SmartGenerator struct {
+	c  *Client
+	sn string
+}
+
+func (s *SmartGenerator) GetSn() string {
+	return s.sn
+}
+
+// SmartGeneratorStatus is the state of a Smart Generator
+type SmartGeneratorStatus struct {
+	EngineState SmartGeneratorEngineState
+	FuelType    SmartGeneratorFuelType
+	// FuelLevel is the gasoline tank level or the LPG cylinder level (if the LPG level monitoring is used), %
+	FuelLevel float64
+	// RuntimeMinutes is the runtime of the current start
+	RuntimeMinutes int
+	// OutputWatts is the total output power, AcOutputWatts and DcOutputWatts are the power of the AC outlets and of the DC output
+	OutputWatts     float64
+	AcOutputWatts   float64
+	DcOutputWatts   float64
+	AcOutputEnabled bool
+	EcoModeEnabled  bool
+	// PowerLimitWatts is the max output power, W
+	PowerLimitWatts int
+	// ErrorCode is 0 when there are no errors
+	ErrorCode   int
+	WarningCode int
+}
+
+// Running returns true when the engine is started or running
+func (s *SmartGeneratorStatus) Running() bool {
+	return s.EngineState == SmartGeneratorEngineStarting || s.EngineState == SmartGeneratorEngineRunning
+}
+
+// GetStatus returns the typed state of the generator
+func (s *SmartGenerator) GetStatus(ctx context.Context) (*SmartGeneratorStatus, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSmartGeneratorStatus(params)
+}
+
+// DecodeSmartGeneratorStatus decodes the Smart Generator state from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// Missing parameters have zero values, an error is returned only if the engine state is not found
+func DecodeSmartGeneratorStatus(params map[string]interface{}) (*SmartGeneratorStatus, error) {
+	state, ok := NumericValue(params["pd.engineState"])
+	if !ok {
+		return nil, errors.New("pd.engineState parameter is not found")
+	}
+	value := func(key string) float64 {
+		v, _ := NumericValue(params[key])
+		return v
+	}
+	status := &SmartGeneratorStatus{
+		EngineState:     SmartGeneratorEngineState(state),
+		FuelType:        SmartGeneratorFuelType(value("pd.fuelType")),
+		FuelLevel:       value("pd.oilVal"),
+		RuntimeMinutes:  int(value("pd.runTime")),
+		OutputWatts:     value("pd.wattsOutSum"),
+		AcOutputWatts:   value("pd.acOutWatts"),
+		DcOutputWatts:   value("pd.dcOutWatts"),
+		AcOutputEnabled: value("pd.acEnabled") == 1,
+		EcoModeEnabled:  value("pd.ecoMode") == 1,
+		PowerLimitWatts: int(value("pd.outPwrMax")),
+		ErrorCode:       int(value("pd.errCode")),
+		WarningCode:     int(value("pd.warnCode")),
+	}
+	if status.FuelType == SmartGeneratorFuelTypeLpg {
+		status.FuelLevel = value("pd.lpgLevel")
+	}
+	return status, nil
+}
+
+func (s *SmartGenerator) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
+	return s.c.GetDeviceParameters(ctx, s.sn, params)
+}
+
+func (s *SmartGenerator) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
+	return s.c.GetDeviceAllParameters(ctx, s.sn)
+}
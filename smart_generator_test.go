@@ -0,0 +1,78 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSmartGeneratorStatus(t *testing.T) {
+	client, _ := newTestClient(t, map[string]interface{}{
+		"pd.engineState": 2,
+		"pd.fuelType":    2,
+		"pd.oilVal":      90,
+		"pd.lpgLevel":    35,
+		"pd.runTime":     75,
+		"pd.wattsOutSum": 1100,
+		"pd.acOutWatts":  400,
+		"pd.dcOutWatts":  700,
+		"pd.acEnabled":   1,
+		"pd.ecoMode":     1,
+		"pd.outPwrMax":   1600,
+		"pd.errCode":     0,
+	})
+	status, err := client.GetSmartGenerator("DGEBZ5RH2000001").GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := SmartGeneratorStatus{EngineState: SmartGeneratorEngineRunning, FuelType: SmartGeneratorFuelTypeLpg, FuelLevel: 35,
+		RuntimeMinutes: 75, OutputWatts: 1100, AcOutputWatts: 400, DcOutputWatts: 700, AcOutputEnabled: true, EcoModeEnabled: true,
+		PowerLimitWatts: 1600}
+	if *status != expected {
+		t.Errorf("got %+v, expected %+v", *status, expected)
+	}
+}
+
+func TestDecodeSmartGeneratorStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]interface{}
+		fuelLevel float64
+		running   bool
+		wantErr   bool
+	}{
+		{
+			name:      "Gasoline level",
+			params:    map[string]interface{}{"pd.engineState": 1, "pd.fuelType": 1, "pd.oilVal": 90, "pd.lpgLevel": 35},
+			fuelLevel: 90,
+			running:   true,
+		},
+		{
+			name:      "LPG level",
+			params:    map[string]interface{}{"pd.engineState": 3, "pd.fuelType": 2, "pd.oilVal": 90, "pd.lpgLevel": 35},
+			fuelLevel: 35,
+		},
+		{
+			name:    "Missing engine state",
+			params:  map[string]interface{}{"pd.fuelType": 1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := DecodeSmartGeneratorStatus(tt.params)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %+v", status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status.FuelLevel != tt.fuelLevel || status.Running() != tt.running {
+				t.Errorf("got fuel level %v, running %v, expected %v, %v", status.FuelLevel, status.Running(), tt.fuelLevel, tt.running)
+			}
+		})
+	}
+}
@@ -2,9 +2,10 @@ package ecoflow
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 )
 
@@ -40,6 +41,13 @@ func (s *SmartHomePanel) SetRtcTime(ctx context.Context, t time.Time) (*CmdSetRe
 // SetLoadChannelControl Load channel control
 // { "sn": "SP10ZAW5ZE9E0052", "operateType": "TCP", "params": { "cmdSet": 11, "id": 16, "ch": 1, "ctrlMode": 1, "sta": 1 } }
 func (s *SmartHomePanel) SetLoadChannelControl(ctx context.Context, ch, ctrlMode, sta int) (*CmdSetResponse, error) {
+	if ch < 0 || ch > 9 {
+		return nil, errors.New("ch is out of range. Range 0:9")
+	}
+	if err := validateChannelControl(ctrlMode, sta); err != nil {
+		return nil, err
+	}
+
 	params := make(map[string]interface{})
 
 	params["cmdSet"] = 11
@@ -54,6 +62,13 @@ func (s *SmartHomePanel) SetLoadChannelControl(ctx context.Context, ch, ctrlMode
 // SetStandByChannelControl Standby channel control
 // { "sn": "SP10ZAW5ZE9E0052", "operateType": "TCP", "params": { "cmdSet": 11, "id": 17, "ch": 10, "ctrlMode": 1, "sta": 1 } }
 func (s *SmartHomePanel) SetStandByChannelControl(ctx context.Context, ch, ctrlMode, sta int) (*CmdSetResponse, error) {
+	if ch < 10 || ch > 11 {
+		return nil, errors.New("ch is out of range. Range 10:11")
+	}
+	if err := validateChannelControl(ctrlMode, sta); err != nil {
+		return nil, err
+	}
+
 	params := make(map[string]interface{})
 
 	params["cmdSet"] = 11
@@ -65,12 +80,71 @@ func (s *SmartHomePanel) SetStandByChannelControl(ctx context.Context, ch, ctrlM
 	return s.setParameter(ctx, params)
 }
 
-//TODO: Split-phase information configuration
+// SplitPhaseConfig is the split-phase (240V) configuration of the load circuits. Every pair links two circuits (0-9),
+// a circuit can be linked with only one other circuit
+type SplitPhaseConfig struct {
+	Pairs [][2]int
+}
+
+// Validate checks that the circuits are in range 0:9, the circuits of a pair are different and every circuit is used only once
+func (c SplitPhaseConfig) Validate() error {
+	used := make(map[int]bool)
+	for _, pair := range c.Pairs {
+		for _, ch := range pair {
+			if ch < 0 || ch >= shpLoadCount {
+				return errors.New("split-phase channel is out of range. Range 0:9")
+			}
+			if used[ch] {
+				return fmt.Errorf("split-phase channel %d is linked more than once", ch)
+			}
+			used[ch] = true
+		}
+		if pair[0] == pair[1] {
+			return fmt.Errorf("split-phase channel %d can't be linked with itself", pair[0])
+		}
+	}
+	return nil
+}
+
+// cfgList returns the configuration of all 10 load circuits: linkMark is 1 for the linked circuits and linkCh is the linked circuit
+func (c SplitPhaseConfig) cfgList() []interface{} {
+	list := make([]interface{}, shpLoadCount)
+	for i := range list {
+		list[i] = map[string]interface{}{"linkMark": 0, "linkCh": 0}
+	}
+	for _, pair := range c.Pairs {
+		list[pair[0]] = map[string]interface{}{"linkMark": 1, "linkCh": pair[1]}
+		list[pair[1]] = map[string]interface{}{"linkMark": 1, "linkCh": pair[0]}
+	}
+	return list
+}
+
+// SetSplitPhaseConfiguration Split-phase information configuration
 // { "sn": "SP10ZAW5ZE9E0052", "operateType": "TCP", "params": { "cmdSet": 11, "id": 18, "cfgList": [ { "linkMark": 1, "linkCh": 0 }, { "linkMark": 0, "linkCh": 0 }, { "linkMark": 0, "linkCh": 0 }, { "linkMark": 0, "linkCh": 0 }, { "linkMark": 0, "linkCh": 0 }, { "linkMark": 0, "linkCh": 0 }, { "linkMark": 0, "linkCh": 0 }, { "linkMark": 0, "linkCh": 0 }, { "linkMark": 0, "linkCh": 0 }, { "linkMark": 0, "linkCh": 0 } ] } }
+func (s *SmartHomePanel) SetSplitPhaseConfiguration(ctx context.Context, config SplitPhaseConfig) (*CmdSetResponse, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]interface{})
+
+	params["cmdSet"] = 11
+	params["id"] = 18
+	params["cfgList"] = config.cfgList()
+
+	return s.setParameter(ctx, params)
+}
 
 // SetChannelCurrentConfiguration Channel current configuration (cur: 6, 13, 16, 20, 30)
 // { "sn": "SP10ZAW5ZE9E0052", "operateType": "TCP", "params": { "cmdSet": 11, "id": 20, "chNum": 0, "cur": 6 } }
 func (s *SmartHomePanel) SetChannelCurrentConfiguration(ctx context.Context, chNum, cur int) (*CmdSetResponse, error) {
+	if chNum < 0 || chNum > 9 {
+		return nil, errors.New("chNum is out of range. Range 0:9")
+	}
+	if !slices.Contains(circuitCurrents, cur) {
+		return nil, errors.New("cur is not valid. Allowed values: 6, 13, 16, 20, 30")
+	}
+
 	params := make(map[string]interface{})
 
 	params["cmdSet"] = 11
@@ -116,7 +190,7 @@ func (s *SmartHomePanel) SetChannelEnableStatusConfiguration(ctx context.Context
 	params := make(map[string]interface{})
 
 	params["cmdSet"] = 11
-	params["id"] = 24
+	params["id"] = 26
 	params["chNum"] = chNum
 	params["isEnable"] = enabled
 
@@ -129,6 +203,9 @@ func (s *SmartHomePanel) SetLoadChannelConfiguration(ctx context.Context, chNum
 	if chNum < 0 || chNum > 9 {
 		return nil, errors.New("chNum is out of range. Range 0:9")
 	}
+	if chName == "" {
+		return nil, errors.New("chName must not be empty")
+	}
 
 	params := make(map[string]interface{})
 
@@ -157,11 +234,103 @@ func (s *SmartHomePanel) SetRegionInformation(ctx context.Context, area string)
 	return s.setParameter(ctx, params)
 }
 
-//TODO: Setting the emergency mode
+// EmergencyModeConfig is the configuration of the load circuits powered by the standby batteries during a grid outage
+type EmergencyModeConfig struct {
+	// BackupMode enables the emergency mode
+	BackupMode bool
+	// OverloadMode enables shedding of the low priority circuits when the batteries are overloaded
+	OverloadMode bool
+	// Priorities is the list of the circuits (0-9) powered in the emergency mode, from the highest to the lowest priority.
+	// The circuits that are not in the list are disabled in the emergency mode
+	Priorities []int
+}
+
+// Validate checks that the circuits are in range 0:9 and listed only once
+func (c EmergencyModeConfig) Validate() error {
+	used := make(map[int]bool)
+	for _, ch := range c.Priorities {
+		if ch < 0 || ch >= shpLoadCount {
+			return errors.New("emergency mode channel is out of range. Range 0:9")
+		}
+		if used[ch] {
+			return fmt.Errorf("emergency mode channel %d is listed more than once", ch)
+		}
+		used[ch] = true
+	}
+	return nil
+}
+
+// chSta returns the priority (1 is the highest) and the enabled status of all 10 load circuits.
+// The disabled circuits get the lowest priorities in the order of the circuits
+func (c EmergencyModeConfig) chSta() []interface{} {
+	list := make([]interface{}, shpLoadCount)
+	priority := 1
+	for _, ch := range c.Priorities {
+		list[ch] = map[string]interface{}{"priority": priority, "isEnable": 1}
+		priority++
+	}
+	for ch := range list {
+		if list[ch] == nil {
+			list[ch] = map[string]interface{}{"priority": priority, "isEnable": 0}
+			priority++
+		}
+	}
+	return list
+}
+
+// SetEmergencyMode Setting the emergency mode
+// { "sn": "SP10ZAW5ZE9E0052", "operateType": "TCP", "params": { "cmdSet": 11, "id": 64, "isCfg": 1, "backupMode": 0, "overloadMode": 0, "chSta": [ { "priority": 1, "isEnable": 1 }, { "priority": 2, "isEnable": 1 }, ... 10 items ] } }
+func (s *SmartHomePanel) SetEmergencyMode(ctx context.Context, config EmergencyModeConfig) (*CmdSetResponse, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
 
-//TODO: Setting the scheduled charging job
+	params := make(map[string]interface{})
+
+	params["cmdSet"] = 11
+	params["id"] = 64
+	params["isCfg"] = 1
+	params["backupMode"] = boolFlag(config.BackupMode)
+	params["overloadMode"] = boolFlag(config.OverloadMode)
+	params["chSta"] = config.chSta()
 
-//TODO: Setting the scheduled discharging job
+	return s.setParameter(ctx, params)
+}
+
+// GetEmergencyMode returns the emergency mode configuration ("emergencyStrategy.*" parameters)
+func (s *SmartHomePanel) GetEmergencyMode(ctx context.Context) (*EmergencyModeConfig, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeEmergencyMode(params)
+}
+
+// DecodeEmergencyMode decodes the emergency mode configuration from the Smart Home Panel parameters.
+// The enabled circuits are ordered by their priority
+func DecodeEmergencyMode(params map[string]interface{}) (*EmergencyModeConfig, error) {
+	chSta, ok := params["emergencyStrategy.chSta"].([]interface{})
+	if !ok {
+		return nil, errors.New("emergencyStrategy.chSta parameter is not found")
+	}
+	backupMode, _ := NumericValue(params["emergencyStrategy.backupMode"])
+	overloadMode, _ := NumericValue(params["emergencyStrategy.overloadMode"])
+	config := &EmergencyModeConfig{BackupMode: backupMode == 1, OverloadMode: overloadMode == 1}
+
+	priorities := make(map[int]int)
+	for ch, v := range chSta {
+		m, _ := v.(map[string]interface{})
+		if enabled, _ := mapInt(m, "isEnable"); enabled != 1 {
+			continue
+		}
+		priorities[ch], _ = mapInt(m, "priority")
+		config.Priorities = append(config.Priorities, ch)
+	}
+	sort.SliceStable(config.Priorities, func(i, j int) bool {
+		return priorities[config.Priorities[i]] < priorities[config.Priorities[j]]
+	})
+	return config, nil
+}
 
 // SetConfigurationStatus Setting the configuration status
 // { "sn": "SP10ZAW5ZE9E0052", "operateType": "TCP", "params": { "cmdSet": 11, "id": 7, "cfgSta": 1 } }
@@ -209,23 +378,9 @@ func (s *SmartHomePanel) GetAllParameters(ctx context.Context) (map[string]inter
 }
 
 func (s *SmartHomePanel) setParameter(ctx context.Context, params map[string]interface{}) (*CmdSetResponse, error) {
-	cmdReq := CmdSetRequest{
-		Id:          fmt.Sprint(time.Now().UnixMilli()),
-		OperateType: "TCP",
+	return s.c.SendCommand(ctx, Command{
 		Sn:          s.sn,
+		OperateType: "TCP",
 		Params:      params,
-	}
-
-	jsonData, err := json.Marshal(cmdReq)
-	if err != nil {
-		return nil, err
-	}
-
-	var req map[string]interface{}
-
-	err = json.Unmarshal(jsonData, &req)
-	if err != nil {
-		return nil, err
-	}
-	return s.c.SetDeviceParameter(ctx, req)
+	})
 }
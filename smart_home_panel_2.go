@@ -0,0 +1,202 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Ecoflow documentation: https://developer-eu.ecoflow.com/us/document/shp2
+// Smart Home Panel 2 doesn't support the "cmdSet 11" TCP commands of SmartHomePanel. It has 12 load circuits and up to
+// 3 connected power stations (Delta Pro Ultra or Delta Pro 3), the settings are sent with the cfg header and cmdFunc 12:
+// { "sn":"HD31ZAS4HGB10001", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":12, "dest":2, "needAck":true, "params":{ ... } }
+
+const (
+	shp2CmdFunc         = 12
+	shp2CircuitCount    = 12
+	shp2FirstCircuitNum = 1 // the circuit parameters are numbered from 1: ch1Sta - ch12Sta
+	shp2MaxUnitsCount   = 3
+
+	shp2CircuitStateKey = "ch%dSta"                      // {"loadSta": "LOAD_CH_POWER_ON", "ctrlMode": "RLY_HAND_CTRL_MODE"}
+	shp2CircuitPowerKey = "loadInfo.hall1Watt"           // [120, 0, ...], 12 load circuits
+	shp2UnitKey         = "backupIncreInfo.Energy%dInfo" // {"isConnect": 1, "batteryPercentage": 70, "outputPower": 500}, units 1-3
+
+	shp2CircuitOn     = "LOAD_CH_POWER_ON"
+	shp2CircuitOff    = "LOAD_CH_POWER_OFF"
+	shp2ControlAuto   = "RLY_AUTO_CTRL_MODE"
+	shp2ControlManual = "RLY_HAND_CTRL_MODE"
+
+	shp2MinBackupSoc   = 10
+	shp2MaxBackupSoc   = 100
+	shp2MinChargeSoc   = 50
+	shp2MaxChargeSoc   = 100
+	shp2MinChargeWatts = 500
+	shp2MaxChargeWatts = 7200
+)
+
+type SmartHomePanel2 struct {
+	c  *Client
+	sn string
+}
+
+func (s *SmartHomePanel2) GetSn() string {
+	return s.sn
+}
+
+// SmartHomePanel2Circuit is the state of a load circuit (Index 0-11)
+type SmartHomePanel2Circuit struct {
+	Index       int
+	ControlMode CircuitControlMode
+	// On is the relay state
+	On    bool
+	Watts float64
+}
+
+// SmartHomePanel2Unit is the state of a connected power station (Index 0-2)
+type SmartHomePanel2Unit struct {
+	Index     int
+	Connected bool
+	Soc       float64
+	// Watts is the power of the unit: positive when charging, negative when discharging
+	Watts float64
+}
+
+// SmartHomePanel2Status is the state of a Smart Home Panel 2
+type SmartHomePanel2Status struct {
+	Circuits [shp2CircuitCount]SmartHomePanel2Circuit
+	Units    []SmartHomePanel2Unit
+	// BackupSoc is the level of all the connected power stations, %
+	BackupSoc float64
+	// BackupReserveSoc is the battery level kept for the grid outage, %
+	BackupReserveSoc int
+	// GridChargeSoc and GridChargeWatts are the limits of the power stations charging from the grid
+	GridChargeSoc   int
+	GridChargeWatts int
+	// GridWatts is the power drawn from the grid
+	GridWatts float64
+}
+
+// GetStatus returns the typed state of the panel
+func (s *SmartHomePanel2) GetStatus(ctx context.Context) (*SmartHomePanel2Status, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSmartHomePanel2Status(params)
+}
+
+// DecodeSmartHomePanel2Status decodes the Smart Home Panel 2 state from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// Missing parameters have zero values, an error is returned only if the circuits power is not found
+func DecodeSmartHomePanel2Status(params map[string]interface{}) (*SmartHomePanel2Status, error) {
+	watts, ok := params[shp2CircuitPowerKey].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s parameter is not found", shp2CircuitPowerKey)
+	}
+	value := func(key string) float64 {
+		v, _ := NumericValue(params[key])
+		return v
+	}
+	status := &SmartHomePanel2Status{
+		BackupSoc:        value("backupIncreInfo.backupBatPer"),
+		BackupReserveSoc: int(value("backupReserveSoc")),
+		GridChargeSoc:    int(value("foceChargeHight")),
+		GridChargeWatts:  int(value("chargeWattPower")),
+		GridWatts:        value("gridWatt"),
+		Units:            make([]SmartHomePanel2Unit, 0, shp2MaxUnitsCount),
+	}
+	for i := range status.Circuits {
+		circuit := SmartHomePanel2Circuit{Index: i, ControlMode: CircuitControlModeAuto}
+		if i < len(watts) {
+			circuit.Watts, _ = NumericValue(watts[i])
+		}
+		state := objectValue(params, fmt.Sprintf(shp2CircuitStateKey, i+shp2FirstCircuitNum))
+		circuit.On = state["loadSta"] == shp2CircuitOn
+		if state["ctrlMode"] == shp2ControlManual {
+			circuit.ControlMode = CircuitControlModeManual
+		}
+		status.Circuits[i] = circuit
+	}
+	for i := 0; i < shp2MaxUnitsCount; i++ {
+		unit := objectValue(params, fmt.Sprintf(shp2UnitKey, i+1))
+		if len(unit) == 0 {
+			continue
+		}
+		status.Units = append(status.Units, SmartHomePanel2Unit{
+			Index:     i,
+			Connected: mapFloat(unit, "isConnect") == 1,
+			Soc:       mapFloat(unit, "batteryPercentage"),
+			Watts:     mapFloat(unit, "outputPower"),
+		})
+	}
+	return status, nil
+}
+
+// SetCircuit Switching the load circuit on or off (index 0:11), the circuit is switched to the manual control mode
+// { "sn":"HD31ZAS4HGB10001", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":12, "dest":2, "needAck":true,
+// "params":{ "ch1Sta":{ "loadSta":"LOAD_CH_POWER_ON", "ctrlMode":"RLY_HAND_CTRL_MODE" } } }
+func (s *SmartHomePanel2) SetCircuit(ctx context.Context, index int, on bool) (*CmdSetResponse, error) {
+	loadSta := shp2CircuitOff
+	if on {
+		loadSta = shp2CircuitOn
+	}
+	return s.setCircuitState(ctx, index, loadSta, shp2ControlManual)
+}
+
+// SetCircuitAuto Switching the load circuit (index 0:11) to the automatic control mode: the panel controls the relay
+// { "sn":"HD31ZAS4HGB10001", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":12, "dest":2, "needAck":true,
+// "params":{ "ch1Sta":{ "loadSta":"LOAD_CH_POWER_ON", "ctrlMode":"RLY_AUTO_CTRL_MODE" } } }
+func (s *SmartHomePanel2) SetCircuitAuto(ctx context.Context, index int) (*CmdSetResponse, error) {
+	return s.setCircuitState(ctx, index, shp2CircuitOn, shp2ControlAuto)
+}
+
+func (s *SmartHomePanel2) setCircuitState(ctx context.Context, index int, loadSta, ctrlMode string) (*CmdSetResponse, error) {
+	if index < 0 || index >= shp2CircuitCount {
+		return nil, errors.New("circuit out of range. Range 0:11")
+	}
+	state := make(map[string]interface{})
+	state["loadSta"] = loadSta
+	state["ctrlMode"] = ctrlMode
+	params := make(map[string]interface{})
+	params[fmt.Sprintf(shp2CircuitStateKey, index+shp2FirstCircuitNum)] = state
+	return s.setParameter(ctx, params)
+}
+
+// SetBackupReserve Setting the battery level kept for the grid outage (range 10:100)
+// { "sn":"HD31ZAS4HGB10001", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":12, "dest":2, "needAck":true, "params":{ "backupReserveSoc":30 } }
+func (s *SmartHomePanel2) SetBackupReserve(ctx context.Context, soc int) (*CmdSetResponse, error) {
+	if soc < shp2MinBackupSoc || soc > shp2MaxBackupSoc {
+		return nil, errors.New("backupReserveSoc out of range. Range 10:100")
+	}
+	params := make(map[string]interface{})
+	params["backupReserveSoc"] = soc
+	return s.setParameter(ctx, params)
+}
+
+// SetGridChargeLimits Setting the limits of the power stations charging from the grid:
+// the charge limit (foceChargeHight range 50:100) and the charging power (chargeWattPower range 500:7200)
+// { "sn":"HD31ZAS4HGB10001", "cmdId":17, "dirDest":1, "dirSrc":1, "cmdFunc":12, "dest":2, "needAck":true, "params":{ "foceChargeHight":90, "chargeWattPower":2000 } }
+func (s *SmartHomePanel2) SetGridChargeLimits(ctx context.Context, soc, watts int) (*CmdSetResponse, error) {
+	if soc < shp2MinChargeSoc || soc > shp2MaxChargeSoc {
+		return nil, errors.New("foceChargeHight out of range. Range 50:100")
+	}
+	if watts < shp2MinChargeWatts || watts > shp2MaxChargeWatts {
+		return nil, errors.New("chargeWattPower out of range. Range 500:7200")
+	}
+	params := make(map[string]interface{})
+	// "foceChargeHight" is the name used by the device
+	params["foceChargeHight"] = soc
+	params["chargeWattPower"] = watts
+	return s.setParameter(ctx, params)
+}
+
+func (s *SmartHomePanel2) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
+	return s.c.GetDeviceParameters(ctx, s.sn, params)
+}
+
+func (s *SmartHomePanel2) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
+	return s.c.GetDeviceAllParameters(ctx, s.sn)
+}
+
+func (s *SmartHomePanel2) setParameter(ctx context.Context, params map[string]interface{}) (*CmdSetResponse, error) {
+	return s.c.setCfgFuncParameter(ctx, s.sn, shp2CmdFunc, params)
+}
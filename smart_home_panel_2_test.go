@@ -0,0 +1,103 @@
+package ecoflow
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSmartHomePanel2Status(t *testing.T) {
+	watts := make([]interface{}, shp2CircuitCount)
+	for i := range watts {
+		watts[i] = 0
+	}
+	watts[2] = 350
+	client, _ := newTestClient(t, map[string]interface{}{
+		"loadInfo.hall1Watt":           watts,
+		"ch3Sta":                       map[string]interface{}{"loadSta": "LOAD_CH_POWER_ON", "ctrlMode": "RLY_HAND_CTRL_MODE"},
+		"ch4Sta.loadSta":               "LOAD_CH_POWER_ON",
+		"ch4Sta.ctrlMode":              "RLY_AUTO_CTRL_MODE",
+		"backupIncreInfo.backupBatPer": 64,
+		"backupIncreInfo.Energy1Info":  map[string]interface{}{"isConnect": 1, "batteryPercentage": 70, "outputPower": -500},
+		"backupIncreInfo.Energy2Info":  map[string]interface{}{"isConnect": 1, "batteryPercentage": 58},
+		"backupReserveSoc":             20,
+		"foceChargeHight":              90,
+		"chargeWattPower":              2000,
+	})
+	status, err := client.GetSmartHomePanel2("HD31ZAS4HGB10001").GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Circuits[2] != (SmartHomePanel2Circuit{Index: 2, ControlMode: CircuitControlModeManual, On: true, Watts: 350}) {
+		t.Errorf("unexpected circuit %+v", status.Circuits[2])
+	}
+	if status.Circuits[3] != (SmartHomePanel2Circuit{Index: 3, ControlMode: CircuitControlModeAuto, On: true}) {
+		t.Errorf("unexpected circuit %+v", status.Circuits[3])
+	}
+	if status.Circuits[11].On {
+		t.Errorf("expected circuit 11 to be off")
+	}
+	if len(status.Units) != 2 || status.Units[0] != (SmartHomePanel2Unit{Index: 0, Connected: true, Soc: 70, Watts: -500}) {
+		t.Errorf("unexpected units %+v", status.Units)
+	}
+	if status.BackupSoc != 64 || status.BackupReserveSoc != 20 || status.GridChargeSoc != 90 || status.GridChargeWatts != 2000 {
+		t.Errorf("unexpected status %+v", *status)
+	}
+
+	if _, err = DecodeSmartHomePanel2Status(map[string]interface{}{}); err == nil {
+		t.Errorf("expected error for missing circuits power")
+	}
+}
+
+func TestSmartHomePanel2Setters(t *testing.T) {
+	client, last := newTestClient(t, nil)
+	panel := client.GetSmartHomePanel2("HD31ZAS4HGB10001")
+
+	runSetterTests(t, last, []setterTest{
+		{
+			name: "Last circuit off",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return panel.SetCircuit(ctx, 11, false) },
+			params: map[string]interface{}{"ch12Sta": map[string]interface{}{"loadSta": "LOAD_CH_POWER_OFF",
+				"ctrlMode": "RLY_HAND_CTRL_MODE"}},
+		},
+		{
+			name: "First circuit auto",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return panel.SetCircuitAuto(ctx, 0) },
+			params: map[string]interface{}{"ch1Sta": map[string]interface{}{"loadSta": "LOAD_CH_POWER_ON",
+				"ctrlMode": "RLY_AUTO_CTRL_MODE"}},
+		},
+		{
+			name:   "Backup reserve",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return panel.SetBackupReserve(ctx, 30) },
+			params: map[string]interface{}{"backupReserveSoc": float64(30)},
+		},
+		{
+			name:   "Grid charge limits",
+			call:   func(ctx context.Context) (*CmdSetResponse, error) { return panel.SetGridChargeLimits(ctx, 95, 3000) },
+			params: map[string]interface{}{"foceChargeHight": float64(95), "chargeWattPower": float64(3000)},
+		},
+		{
+			name: "Circuit out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return panel.SetCircuit(ctx, 12, true) },
+		},
+		{
+			name: "Backup reserve out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return panel.SetBackupReserve(ctx, 5) },
+		},
+		{
+			name: "Grid charge limit out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return panel.SetGridChargeLimits(ctx, 40, 2000) },
+		},
+		{
+			name: "Grid charging power out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return panel.SetGridChargeLimits(ctx, 90, 100) },
+		},
+	})
+
+	// the settings are sent to the panel module
+	if _, err := panel.SetBackupReserve(context.Background(), 20); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if (*last)["cmdFunc"] != float64(12) {
+		t.Errorf("unexpected request %v", *last)
+	}
+}
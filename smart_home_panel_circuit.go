@@ -0,0 +1,207 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Smart Home Panel parameters that describe the circuits, every parameter is an array with an item per circuit
+const (
+	shpLoadInfoKey         = "loadChInfo.info"                // [{"chName": "Kitchen", "iconInfo": 10}, ...], 10 load circuits
+	shpLoadCurrentKey      = "loadChCurInfo.cur"              // [16, 20, ...], 10 load circuits
+	shpLoadControlKey      = "heartbeat.loadCmdChCtrlInfos"   // [{"ctrlMode": 0, "ctrlSta": 1, "isEnable": 1}, ...], 10 load circuits
+	shpStandbyControlKey   = "heartbeat.backupCmdChCtrlInfos" // [{"ctrlMode": 0, "ctrlSta": 1, "isEnable": 1}, ...], 2 standby channels
+	shpCircuitPowerKey     = "wattInfo.chWatt"                // [120, 0, ...], 10 load circuits followed by 2 standby channels
+	shpFirstStandbyCircuit = 10
+)
+
+// circuitCurrents are the supported current ratings of the load circuits, A
+var circuitCurrents = []int{6, 13, 16, 20, 30}
+
+// CircuitControlMode defines who controls the circuit relay
+type CircuitControlMode int
+
+const (
+	CircuitControlModeAuto   CircuitControlMode = 0
+	CircuitControlModeManual CircuitControlMode = 1
+)
+
+// Circuit is one of 10 load circuits (Index 0-9) or 2 standby channels (Index 10-11) of the Smart Home Panel.
+// The fields are the state at the moment of GetCircuits call, they are updated by the methods when the command succeeds
+type Circuit struct {
+	panel *SmartHomePanel
+
+	Index int
+	// Standby is true for the standby channels (the batteries)
+	Standby bool
+	// Name and Icon are set only for the load circuits
+	Name string
+	Icon int
+	// Current is the current rating of the load circuit, A
+	Current     int
+	Enabled     bool
+	ControlMode CircuitControlMode
+	// On is the relay state
+	On bool
+	// Watts is the power of the circuit
+	Watts float64
+}
+
+// GetCircuits returns 10 load circuits and 2 standby channels
+func (s *SmartHomePanel) GetCircuits(ctx context.Context) ([]*Circuit, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.CircuitsFromParameters(params), nil
+}
+
+// CircuitsFromParameters builds 10 load circuits and 2 standby channels from the panel parameters (see GetAllParameters).
+// The fields missing in the parameters are left empty
+func (s *SmartHomePanel) CircuitsFromParameters(params map[string]interface{}) []*Circuit {
+	info, _ := params[shpLoadInfoKey].([]interface{})
+	currents, _ := params[shpLoadCurrentKey].([]interface{})
+	loadControl, _ := params[shpLoadControlKey].([]interface{})
+	standbyControl, _ := params[shpStandbyControlKey].([]interface{})
+	watts, _ := params[shpCircuitPowerKey].([]interface{})
+
+	item := func(list []interface{}, i int) interface{} {
+		if i < len(list) {
+			return list[i]
+		}
+		return nil
+	}
+
+	circuits := make([]*Circuit, 0, shpLoadCount+shpStandbyCount)
+	for i := 0; i < shpLoadCount+shpStandbyCount; i++ {
+		c := &Circuit{panel: s, Index: i, Standby: i >= shpFirstStandbyCircuit}
+		var control map[string]interface{}
+		if c.Standby {
+			control, _ = item(standbyControl, i-shpFirstStandbyCircuit).(map[string]interface{})
+		} else {
+			control, _ = item(loadControl, i).(map[string]interface{})
+			if m, ok := item(info, i).(map[string]interface{}); ok {
+				c.Name, _ = m["chName"].(string)
+				c.Icon, _ = mapInt(m, "iconInfo")
+			}
+			if cur, ok := NumericValue(item(currents, i)); ok {
+				c.Current = int(cur)
+			}
+		}
+		if control != nil {
+			mode, _ := mapInt(control, "ctrlMode")
+			c.ControlMode = CircuitControlMode(mode)
+			sta, _ := mapInt(control, "ctrlSta")
+			c.On = sta == 1
+			enabled, _ := mapInt(control, "isEnable")
+			c.Enabled = enabled == 1
+		}
+		c.Watts, _ = NumericValue(item(watts, i))
+		circuits = append(circuits, c)
+	}
+	return circuits
+}
+
+// TurnOn switches the relay on in the manual mode
+func (c *Circuit) TurnOn(ctx context.Context) error {
+	return c.control(ctx, CircuitControlModeManual, true)
+}
+
+// TurnOff switches the relay off in the manual mode
+func (c *Circuit) TurnOff(ctx context.Context) error {
+	return c.control(ctx, CircuitControlModeManual, false)
+}
+
+// SetAuto returns the control of the relay to the panel
+func (c *Circuit) SetAuto(ctx context.Context) error {
+	return c.control(ctx, CircuitControlModeAuto, c.On)
+}
+
+// Rename sets the name of the load circuit, the icon is not changed
+func (c *Circuit) Rename(ctx context.Context, name string) error {
+	if c.Standby {
+		return errors.New("standby channel can't be renamed")
+	}
+	if err := checkCmdResponse(c.panel.SetLoadChannelConfiguration(ctx, c.Index, name, c.Icon)); err != nil {
+		return err
+	}
+	c.Name = name
+	return nil
+}
+
+// SetIcon sets the icon of the load circuit, the name is not changed
+func (c *Circuit) SetIcon(ctx context.Context, icon int) error {
+	if c.Standby {
+		return errors.New("standby channel icon can't be changed")
+	}
+	if err := checkCmdResponse(c.panel.SetLoadChannelConfiguration(ctx, c.Index, c.Name, icon)); err != nil {
+		return err
+	}
+	c.Icon = icon
+	return nil
+}
+
+// SetCurrent sets the current rating of the load circuit (6, 13, 16, 20 or 30 A)
+func (c *Circuit) SetCurrent(ctx context.Context, cur int) error {
+	if c.Standby {
+		return errors.New("standby channel current can't be changed")
+	}
+	if err := checkCmdResponse(c.panel.SetChannelCurrentConfiguration(ctx, c.Index, cur)); err != nil {
+		return err
+	}
+	c.Current = cur
+	return nil
+}
+
+// SetEnabled enables or disables the load circuit
+func (c *Circuit) SetEnabled(ctx context.Context, enabled bool) error {
+	if c.Standby {
+		return errors.New("standby channel can't be enabled or disabled")
+	}
+	if err := checkCmdResponse(c.panel.SetChannelEnableStatusConfiguration(ctx, c.Index, SettingSwitcher(boolFlag(enabled)))); err != nil {
+		return err
+	}
+	c.Enabled = enabled
+	return nil
+}
+
+func (c *Circuit) control(ctx context.Context, mode CircuitControlMode, on bool) error {
+	var err error
+	if c.Standby {
+		err = checkCmdResponse(c.panel.SetStandByChannelControl(ctx, c.Index, int(mode), boolFlag(on)))
+	} else {
+		err = checkCmdResponse(c.panel.SetLoadChannelControl(ctx, c.Index, int(mode), boolFlag(on)))
+	}
+	if err != nil {
+		return err
+	}
+	c.ControlMode = mode
+	c.On = on
+	return nil
+}
+
+// validateChannelControl checks the arguments of the channel control commands (ctrlMode: 0 auto, 1 manual; sta: 0 off, 1 on)
+func validateChannelControl(ctrlMode, sta int) error {
+	if ctrlMode != int(CircuitControlModeAuto) && ctrlMode != int(CircuitControlModeManual) {
+		return errors.New("ctrlMode is out of range. Range 0:1")
+	}
+	if sta < 0 || sta > 1 {
+		return errors.New("sta is out of range. Range 0:1")
+	}
+	return nil
+}
+
+// checkCmdResponse returns an error if the command failed or the device rejected it
+func checkCmdResponse(resp *CmdSetResponse, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return errors.New("empty response")
+	}
+	if resp.Code != "0" {
+		return fmt.Errorf("command failed, error code %s, error message %s", resp.Code, resp.Message)
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+package ecoflow
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+const (
+	shpJobsCount        = 10
+	shpStandbyCount     = 2
+	shpLoadCount        = 10
+	shpTimeSlot         = 10 * time.Minute
+	shpTimeScaleLength  = 18 // 144 ten-minute slots of the day, one bit per slot
+	shpTimeModeDaily    = 0
+	shpTimeModeWeekly   = 1
+	shpJobTypeCharge    = 1
+	shpJobTypeDischarge = 2
+)
+
+// Weekdays is a set of days of the week, bit n is set for time.Weekday(n)
+type Weekdays uint8
+
+const EveryDay Weekdays = 1<<7 - 1
+
+// NewWeekdays returns the set of the given days
+func NewWeekdays(days ...time.Weekday) Weekdays {
+	var w Weekdays
+	for _, d := range days {
+		w |= 1 << uint(d)
+	}
+	return w
+}
+
+// Has returns true if the day is in the set
+func (w Weekdays) Has(d time.Weekday) bool {
+	return w&(1<<uint(d)) != 0
+}
+
+// weekdayNames are the names of the days in "mode1" objects of the scheduled jobs and tasks, indexed by time.Weekday
+var weekdayNames = [7]string{"sun", "mon", "tues", "wed", "thur", "fri", "sat"}
+
+// mode1 returns the days as {"sun": 0, "mon": 1, ...} object
+func (w Weekdays) mode1() map[string]interface{} {
+	mode1 := make(map[string]interface{})
+	for d, name := range weekdayNames {
+		mode1[name] = boolFlag(w.Has(time.Weekday(d)))
+	}
+	return mode1
+}
+
+// weekdaysFromMode1 decodes {"sun": 0, "mon": 1, ...} object
+func weekdaysFromMode1(v interface{}) Weekdays {
+	var w Weekdays
+	mode1, _ := v.(map[string]interface{})
+	for d, name := range weekdayNames {
+		if n, _ := mapInt(mode1, name); n == 1 {
+			w |= NewWeekdays(time.Weekday(d))
+		}
+	}
+	return w
+}
+
+// ScheduledJob is the common part of Smart Home Panel scheduled jobs
+type ScheduledJob struct {
+	// Index is the job slot, 0-9
+	Index   int
+	Enabled bool
+	// Weekdays when the job runs
+	Weekdays Weekdays
+	// Start and End are the time of the day (e.g. 22*time.Hour + 30*time.Minute) in 10 minutes steps.
+	// If End is before Start, the job runs over midnight
+	Start time.Duration
+	End   time.Duration
+}
+
+// SmartHomePanelChargeJob is a scheduled charging of the standby batteries from the grid
+type SmartHomePanelChargeJob struct {
+	ScheduledJob
+	// ChargeWatts is the grid charging power
+	ChargeWatts int
+	// Batteries selects the standby channels (batteries) to charge
+	Batteries [shpStandbyCount]bool
+	// MaxSoc is the battery level to stop charging at, 0-100
+	MaxSoc int
+}
+
+// SmartHomePanelDischargeJob is a scheduled powering of the load circuits from the standby batteries.
+// The Smart Home Panel doesn't support setting the discharging power, the circuits consume as much as they need
+type SmartHomePanelDischargeJob struct {
+	ScheduledJob
+	// Circuits selects the load circuits powered by the batteries
+	Circuits [shpLoadCount]bool
+	// MinSoc is the battery level to stop discharging at, 0-100
+	MinSoc int
+}
+
+func (j *ScheduledJob) validate() error {
+	if j.Index < 0 || j.Index >= shpJobsCount {
+		return errors.New("job index is out of range. Range 0:9")
+	}
+	if j.Weekdays == 0 || j.Weekdays&^EveryDay != 0 {
+		return errors.New("job weekdays are not valid, at least one day must be set")
+	}
+	for _, t := range []time.Duration{j.Start, j.End} {
+		if t < 0 || t >= 24*time.Hour {
+			return errors.New("job start/end time is out of range. Range 00:00-23:50")
+		}
+		if t%shpTimeSlot != 0 {
+			return errors.New("job start/end time must be a multiple of 10 minutes")
+		}
+	}
+	if j.Start == j.End {
+		return errors.New("job start and end time must be different")
+	}
+	return nil
+}
+
+func (j *SmartHomePanelChargeJob) validate() error {
+	if err := j.ScheduledJob.validate(); err != nil {
+		return err
+	}
+	if j.ChargeWatts < 1 || j.ChargeWatts > 7200 {
+		return errors.New("chargeWatts is out of range. Range 1:7200")
+	}
+	if j.MaxSoc < 1 || j.MaxSoc > 100 {
+		return errors.New("maxSoc is out of range. Range 1:100")
+	}
+	if j.Batteries == [shpStandbyCount]bool{} {
+		return errors.New("at least one battery must be selected")
+	}
+	return nil
+}
+
+func (j *SmartHomePanelDischargeJob) validate() error {
+	if err := j.ScheduledJob.validate(); err != nil {
+		return err
+	}
+	if j.MinSoc < 0 || j.MinSoc > 99 {
+		return errors.New("minSoc is out of range. Range 0:99")
+	}
+	if j.Circuits == [shpLoadCount]bool{} {
+		return errors.New("at least one circuit must be selected")
+	}
+	return nil
+}
+
+// SetScheduledChargingJob Setting the scheduled charging job
+// { "sn": "SP10ZAW5ZE9E0052", "operateType": "TCP", "params": { "cmdSet": 11, "id": 81, "cfgIndex": 1, "cfg": { "chChargeWatt": 2000, "chSta": [1, 0], "hightBattery": 100, "comCfg": { "timeScale": [255, ...], "isCfg": 1, "type": 1, "timeRange": { "isCfg": 1, "startTime": { "sec": 0, "min": 0, "week": 4, "hour": 0, "month": 1, "year": 2023, "day": 1 }, "timeMode": 0, "endTime": { ... }, "mode1": { "sun": 0, "mon": 0, "tues": 0, "wed": 0, "thur": 0, "fri": 0, "sat": 0 }, "isEnable": 1 }, "isEnable": 1, "setTime": { ... } } } } }
+func (s *SmartHomePanel) SetScheduledChargingJob(ctx context.Context, job SmartHomePanelChargeJob) (*CmdSetResponse, error) {
+	if err := job.validate(); err != nil {
+		return nil, err
+	}
+
+	cfg := make(map[string]interface{})
+	cfg["chChargeWatt"] = job.ChargeWatts
+	cfg["chSta"] = boolFlags(job.Batteries[:])
+	cfg["hightBattery"] = job.MaxSoc
+	cfg["comCfg"] = job.ScheduledJob.comCfg(shpJobTypeCharge, time.Now())
+
+	params := make(map[string]interface{})
+
+	params["cmdSet"] = 11
+	params["id"] = 81
+	params["cfgIndex"] = job.Index
+	params["cfg"] = cfg
+
+	return s.setParameter(ctx, params)
+}
+
+// SetScheduledDischargingJob Setting the scheduled discharging job
+// { "sn": "SP10ZAW5ZE9E0052", "operateType": "TCP", "params": { "cmdSet": 11, "id": 82, "cfgIndex": 1, "cfg": { "chSta": [1, 0, 0, 0, 0, 0, 0, 0, 0, 0], "lowBattery": 95, "comCfg": { ... } } } }
+func (s *SmartHomePanel) SetScheduledDischargingJob(ctx context.Context, job SmartHomePanelDischargeJob) (*CmdSetResponse, error) {
+	if err := job.validate(); err != nil {
+		return nil, err
+	}
+
+	cfg := make(map[string]interface{})
+	cfg["chSta"] = boolFlags(job.Circuits[:])
+	cfg["lowBattery"] = job.MinSoc
+	cfg["comCfg"] = job.ScheduledJob.comCfg(shpJobTypeDischarge, time.Now())
+
+	params := make(map[string]interface{})
+
+	params["cmdSet"] = 11
+	params["id"] = 82
+	params["cfgIndex"] = job.Index
+	params["cfg"] = cfg
+
+	return s.setParameter(ctx, params)
+}
+
+// GetScheduledChargingJobs returns the scheduled charging jobs configured on the panel ordered by index
+func (s *SmartHomePanel) GetScheduledChargingJobs(ctx context.Context) ([]SmartHomePanelChargeJob, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	charge, _ := DecodeScheduledJobs(params)
+	return charge, nil
+}
+
+// GetScheduledDischargingJobs returns the scheduled discharging jobs configured on the panel ordered by index
+func (s *SmartHomePanel) GetScheduledDischargingJobs(ctx context.Context) ([]SmartHomePanelDischargeJob, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, discharge := DecodeScheduledJobs(params)
+	return discharge, nil
+}
+
+// DecodeScheduledJobs finds the scheduled jobs in the Smart Home Panel parameters (see GetAllParameters).
+// A job is an object with "comCfg" field (optionally wrapped into {"cfgIndex": n, "cfg": {...}}), either as a parameter
+// value or as an element of an array parameter. The job index is "cfgIndex" if present, otherwise the position in the array.
+// Charging jobs are recognized by "chChargeWatt"/"hightBattery" fields, discharging jobs by "lowBattery" field
+func DecodeScheduledJobs(params map[string]interface{}) ([]SmartHomePanelChargeJob, []SmartHomePanelDischargeJob) {
+	var charge []SmartHomePanelChargeJob
+	var discharge []SmartHomePanelDischargeJob
+
+	decode := func(index int, v interface{}) {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		if i, ok := mapInt(m, "cfgIndex"); ok {
+			index = i
+		}
+		if cfg, ok := m["cfg"].(map[string]interface{}); ok {
+			m = cfg
+		}
+		comCfg, ok := m["comCfg"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		job := decodeScheduledJob(index, comCfg)
+		_, hasChargeWatts := m["chChargeWatt"]
+		_, hasHighBattery := m["hightBattery"]
+		if hasChargeWatts || hasHighBattery {
+			j := SmartHomePanelChargeJob{ScheduledJob: job}
+			j.ChargeWatts, _ = mapInt(m, "chChargeWatt")
+			j.MaxSoc, _ = mapInt(m, "hightBattery")
+			decodeFlags(m["chSta"], j.Batteries[:])
+			charge = append(charge, j)
+		} else if _, ok := m["lowBattery"]; ok {
+			j := SmartHomePanelDischargeJob{ScheduledJob: job}
+			j.MinSoc, _ = mapInt(m, "lowBattery")
+			decodeFlags(m["chSta"], j.Circuits[:])
+			discharge = append(discharge, j)
+		}
+	}
+
+	for _, v := range params {
+		if list, ok := v.([]interface{}); ok {
+			for i, item := range list {
+				decode(i, item)
+			}
+		} else {
+			decode(0, v)
+		}
+	}
+
+	sort.Slice(charge, func(i, j int) bool { return charge[i].Index < charge[j].Index })
+	sort.Slice(discharge, func(i, j int) bool { return discharge[i].Index < discharge[j].Index })
+	return charge, discharge
+}
+
+// comCfg builds the common job configuration. The dates of start and end time are set to the current date
+func (j *ScheduledJob) comCfg(jobType int, now time.Time) map[string]interface{} {
+	timeMode := shpTimeModeWeekly
+	if j.Weekdays == EveryDay {
+		timeMode = shpTimeModeDaily
+	}
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	timeRange := make(map[string]interface{})
+	timeRange["isCfg"] = 1
+	timeRange["isEnable"] = boolFlag(j.Enabled)
+	timeRange["timeMode"] = timeMode
+	timeRange["mode1"] = j.Weekdays.mode1()
+	timeRange["startTime"] = shpTime(day.Add(j.Start))
+	timeRange["endTime"] = shpTime(day.Add(j.End))
+
+	comCfg := make(map[string]interface{})
+	comCfg["isCfg"] = 1
+	comCfg["isEnable"] = boolFlag(j.Enabled)
+	comCfg["type"] = jobType
+	comCfg["timeScale"] = j.timeScale()
+	comCfg["timeRange"] = timeRange
+	comCfg["setTime"] = shpTime(now)
+	return comCfg
+}
+
+// timeScale returns the ten-minute slots of the day when the job is active, slot n is bit n%8 of byte n/8
+func (j *ScheduledJob) timeScale() []int {
+	scale := make([]int, shpTimeScaleLength)
+	slots := int(24 * time.Hour / shpTimeSlot)
+	start, end := int(j.Start/shpTimeSlot), int(j.End/shpTimeSlot)
+	for slot := start; slot != end; slot = (slot + 1) % slots {
+		scale[slot/8] |= 1 << (slot % 8)
+	}
+	return scale
+}
+
+func decodeScheduledJob(index int, comCfg map[string]interface{}) ScheduledJob {
+	job := ScheduledJob{Index: index}
+	enabled, _ := mapInt(comCfg, "isEnable")
+	job.Enabled = enabled == 1
+
+	timeRange, _ := comCfg["timeRange"].(map[string]interface{})
+	if timeMode, _ := mapInt(timeRange, "timeMode"); timeMode == shpTimeModeDaily {
+		job.Weekdays = EveryDay
+	} else {
+		job.Weekdays = weekdaysFromMode1(timeRange["mode1"])
+	}
+	job.Start = shpTimeOfDay(timeRange["startTime"])
+	job.End = shpTimeOfDay(timeRange["endTime"])
+	return job
+}
+
+// shpTime converts the time to the Smart Home Panel format
+func shpTime(t time.Time) map[string]interface{} {
+	result := make(map[string]interface{})
+	result["year"] = t.Year()
+	result["month"] = int(t.Month())
+	result["day"] = t.Day()
+	result["week"] = int(t.Weekday())
+	result["hour"] = t.Hour()
+	result["min"] = t.Minute()
+	result["sec"] = t.Second()
+	return result
+}
+
+func shpTimeOfDay(v interface{}) time.Duration {
+	m, _ := v.(map[string]interface{})
+	hour, _ := mapInt(m, "hour")
+	minute, _ := mapInt(m, "min")
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+}
+
+// mapInt returns the numeric value of the map key as int
+func mapInt(m map[string]interface{}, key string) (int, bool) {
+	v, ok := NumericValue(m[key])
+	return int(v), ok
+}
+
+func boolFlag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func boolFlags(values []bool) []int {
+	result := make([]int, len(values))
+	for i, v := range values {
+		result[i] = boolFlag(v)
+	}
+	return result
+}
+
+func decodeFlags(v interface{}, dst []bool) {
+	list, _ := v.([]interface{})
+	for i := 0; i < len(list) && i < len(dst); i++ {
+		n, _ := NumericValue(list[i])
+		dst[i] = n == 1
+	}
+}
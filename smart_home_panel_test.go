@@ -0,0 +1,224 @@
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client connected to a test server that stores the body of the last set request
+func newTestClient(t *testing.T, quota map[string]interface{}) (*Client, *map[string]interface{}) {
+	t.Helper()
+	var last map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			body, _ := io.ReadAll(r.Body)
+			last = nil
+			if err := json.Unmarshal(body, &last); err != nil {
+				t.Errorf("invalid request body: %v", err)
+			}
+			_, _ = w.Write([]byte(`{"code":"0","message":"Success"}`))
+			return
+		}
+		response, _ := json.Marshal(map[string]interface{}{"code": "0", "message": "Success", "data": quota})
+		_, _ = w.Write(response)
+	}))
+	t.Cleanup(server.Close)
+	return NewEcoflowClient("access", "secret", WithBaseUrl(server.URL), WithHttpClient(server.Client())), &last
+}
+
+// setterTest is a table case for the device setters. params are the expected parameters of the sent command,
+// nil if the call must fail without sending anything. operateType and cmdCode are checked if not empty
+type setterTest struct {
+	name        string
+	call        func(ctx context.Context) (*CmdSetResponse, error)
+	operateType string
+	cmdCode     string
+	params      map[string]interface{}
+}
+
+func runSetterTests(t *testing.T, last *map[string]interface{}, tests []setterTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*last = nil
+			_, err := tt.call(context.Background())
+			if tt.params == nil {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				}
+				if *last != nil {
+					t.Errorf("unexpected request %v", *last)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.operateType != "" && (*last)["operateType"] != tt.operateType {
+				t.Errorf("got operateType %v, expected %s", (*last)["operateType"], tt.operateType)
+			}
+			if tt.cmdCode != "" && (*last)["cmdCode"] != tt.cmdCode {
+				t.Errorf("got cmdCode %v, expected %s", (*last)["cmdCode"], tt.cmdCode)
+			}
+			if !reflect.DeepEqual((*last)["params"], tt.params) {
+				t.Errorf("got params %v, expected %v", (*last)["params"], tt.params)
+			}
+		})
+	}
+}
+
+func TestSmartHomePanelScheduledJobs(t *testing.T) {
+	client, last := newTestClient(t, nil)
+	shp := client.GetSmartHomePanel("SP10ZAW5ZE9E0052")
+
+	job := SmartHomePanelChargeJob{
+		ScheduledJob: ScheduledJob{
+			Index:    2,
+			Enabled:  true,
+			Weekdays: NewWeekdays(time.Monday, time.Friday),
+			Start:    23 * time.Hour,
+			End:      time.Hour,
+		},
+		ChargeWatts: 2000,
+		Batteries:   [2]bool{true, false},
+		MaxSoc:      90,
+	}
+	if _, err := shp.SetScheduledChargingJob(context.Background(), job); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	params := (*last)["params"].(map[string]interface{})
+	if params["id"] != float64(81) || params["cfgIndex"] != float64(2) {
+		t.Errorf("unexpected params %v", params)
+	}
+	comCfg := params["cfg"].(map[string]interface{})["comCfg"].(map[string]interface{})
+	scale := comCfg["timeScale"].([]interface{})
+	// 23:00-01:00 is slots 138-143 and 0-5
+	if scale[0] != float64(0x3f) || scale[17] != float64(0xfc) || scale[8] != float64(0) {
+		t.Errorf("unexpected time scale %v", scale)
+	}
+
+	// the job is read back in the same format
+	charge, discharge := DecodeScheduledJobs(map[string]interface{}{"timeTask.cfgList": []interface{}{params}})
+	if len(charge) != 1 || len(discharge) != 0 {
+		t.Fatalf("expected one charging job, got %v %v", charge, discharge)
+	}
+	if charge[0] != job {
+		t.Errorf("got %+v, expected %+v", charge[0], job)
+	}
+
+	job.Start = 23*time.Hour + 5*time.Minute
+	if _, err := shp.SetScheduledChargingJob(context.Background(), job); err == nil {
+		t.Errorf("expected error for start time not aligned to 10 minutes")
+	}
+	if _, err := shp.SetScheduledDischargingJob(context.Background(), SmartHomePanelDischargeJob{
+		ScheduledJob: ScheduledJob{Index: 0, Weekdays: EveryDay, Start: 0, End: time.Hour},
+	}); err == nil {
+		t.Errorf("expected error for discharging job without circuits")
+	}
+}
+
+func TestSmartHomePanelSplitPhaseAndEmergencyMode(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{
+		"emergencyStrategy.backupMode":   1,
+		"emergencyStrategy.overloadMode": 0,
+		"emergencyStrategy.chSta": []interface{}{
+			map[string]interface{}{"priority": 3, "isEnable": 1},
+			map[string]interface{}{"priority": 1, "isEnable": 1},
+			map[string]interface{}{"priority": 2, "isEnable": 0},
+		},
+	})
+	shp := client.GetSmartHomePanel("SP10ZAW5ZE9E0052")
+	ctx := context.Background()
+
+	if _, err := shp.SetSplitPhaseConfiguration(ctx, SplitPhaseConfig{Pairs: [][2]int{{0, 1}, {1, 2}}}); err == nil {
+		t.Errorf("expected error for channel linked twice")
+	}
+	if _, err := shp.SetSplitPhaseConfiguration(ctx, SplitPhaseConfig{Pairs: [][2]int{{2, 3}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfgList := (*last)["params"].(map[string]interface{})["cfgList"].([]interface{})
+	if len(cfgList) != 10 {
+		t.Fatalf("expected 10 cfgList entries, got %d", len(cfgList))
+	}
+	if link := cfgList[3].(map[string]interface{}); link["linkMark"] != float64(1) || link["linkCh"] != float64(2) {
+		t.Errorf("unexpected link of channel 3: %v", link)
+	}
+
+	if _, err := shp.SetEmergencyMode(ctx, EmergencyModeConfig{BackupMode: true, Priorities: []int{4, 0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chSta := (*last)["params"].(map[string]interface{})["chSta"].([]interface{})
+	if ch := chSta[4].(map[string]interface{}); ch["priority"] != float64(1) || ch["isEnable"] != float64(1) {
+		t.Errorf("unexpected status of channel 4: %v", ch)
+	}
+	if ch := chSta[1].(map[string]interface{}); ch["isEnable"] != float64(0) {
+		t.Errorf("unexpected status of channel 1: %v", ch)
+	}
+
+	config, err := shp.GetEmergencyMode(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !config.BackupMode || config.OverloadMode || len(config.Priorities) != 2 || config.Priorities[0] != 1 || config.Priorities[1] != 0 {
+		t.Errorf("unexpected emergency mode %+v", config)
+	}
+}
+
+func TestSmartHomePanelCircuits(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{
+		"loadChInfo.info":              []interface{}{map[string]interface{}{"chName": "Kitchen", "iconInfo": 10}},
+		"loadChCurInfo.cur":            []interface{}{16},
+		"heartbeat.loadCmdChCtrlInfos": []interface{}{map[string]interface{}{"ctrlMode": 0, "ctrlSta": 1, "isEnable": 1}},
+		"wattInfo.chWatt":              []interface{}{120, 0, 0, 0, 0, 0, 0, 0, 0, 0, 35, 0},
+	})
+	ctx := context.Background()
+
+	circuits, err := client.GetSmartHomePanel("SP10ZAW5ZE9E0052").GetCircuits(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(circuits) != 12 {
+		t.Fatalf("expected 12 circuits, got %d", len(circuits))
+	}
+	kitchen := circuits[0]
+	if kitchen.Name != "Kitchen" || kitchen.Icon != 10 || kitchen.Current != 16 || !kitchen.On || !kitchen.Enabled ||
+		kitchen.ControlMode != CircuitControlModeAuto || kitchen.Watts != 120 {
+		t.Errorf("unexpected circuit %+v", kitchen)
+	}
+	if !circuits[10].Standby || circuits[10].Watts != 35 {
+		t.Errorf("unexpected standby channel %+v", circuits[10])
+	}
+
+	if err = kitchen.TurnOff(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	params := (*last)["params"].(map[string]interface{})
+	if params["id"] != float64(16) || params["ch"] != float64(0) || params["ctrlMode"] != float64(1) || params["sta"] != float64(0) {
+		t.Errorf("unexpected params %v", params)
+	}
+	if kitchen.On || kitchen.ControlMode != CircuitControlModeManual {
+		t.Errorf("circuit state is not updated %+v", kitchen)
+	}
+
+	if err = kitchen.Rename(ctx, "Oven"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	info := (*last)["params"].(map[string]interface{})["info"].(map[string]interface{})
+	if info["chName"] != "Oven" || info["iconInfo"] != float64(10) {
+		t.Errorf("unexpected info %v", info)
+	}
+
+	if err = kitchen.SetCurrent(ctx, 25); err == nil {
+		t.Errorf("expected error for unsupported current")
+	}
+	if err = circuits[11].Rename(ctx, "Battery"); err == nil {
+		t.Errorf("expected error for standby channel rename")
+	}
+}
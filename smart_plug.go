@@ -2,13 +2,33 @@ package ecoflow
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"time"
 )
 
 // Ecoflow documentation: https://developer-eu.ecoflow.com/us/document/smartPlug
+// The max power limit, the overload protection and the power-on state can't be set: the Open API documents only the
+// relay, brightness and delete task commands. The limit and the protection flag are reported in the status
+
+// SmartPlugStatus is the state of a Smart Plug decoded from "2_1.*" parameters
+type SmartPlugStatus struct {
+	// Watts is the load power, W
+	Watts float64
+	// Current is the load current, A
+	Current float64
+	// Voltage is the grid voltage, V
+	Voltage float64
+	// Frequency is the grid frequency, Hz
+	Frequency float64
+	// Temperature is the plug temperature, ℃
+	Temperature float64
+	RelayOn     bool
+	Brightness  int
+	// MaxWatts is the max power limit, W
+	MaxWatts       int
+	WattsProtected bool
+	ErrorCode      int
+	WarningCode    int
+}
 
 type SmartPlug struct {
 	c  *Client
@@ -49,26 +69,21 @@ func (s *SmartPlug) DeleteScheduledTasks(ctx context.Context, taskIndex int) (*C
 	return s.setParameter(ctx, "WN511_SOCKET_DELETE_TIME_TASK", params)
 }
 
-func (s *SmartPlug) setParameter(ctx context.Context, cmdCode string, params map[string]interface{}) (*CmdSetResponse, error) {
-	cmdReq := CmdSetRequest{
-		Id:      fmt.Sprint(time.Now().UnixMilli()),
-		CmdCode: cmdCode,
-		Sn:      s.sn,
-		Params:  params,
-	}
-
-	jsonData, err := json.Marshal(cmdReq)
+// GetScheduledTasks returns the scheduled tasks ordered by index
+func (s *SmartPlug) GetScheduledTasks(ctx context.Context) ([]PlugTask, error) {
+	params, err := s.GetAllParameters(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return DecodePlugTasks(params), nil
+}
 
-	var req map[string]interface{}
-
-	err = json.Unmarshal(jsonData, &req)
-	if err != nil {
-		return nil, err
-	}
-	return s.c.SetDeviceParameter(ctx, req)
+func (s *SmartPlug) setParameter(ctx context.Context, cmdCode string, params map[string]interface{}) (*CmdSetResponse, error) {
+	return s.c.SendCommand(ctx, Command{
+		Sn:      s.sn,
+		CmdCode: cmdCode,
+		Params:  params,
+	})
 }
 
 func (s *SmartPlug) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
@@ -78,3 +93,38 @@ func (s *SmartPlug) GetParameter(ctx context.Context, params []string) (*GetCmdR
 func (s *SmartPlug) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
 	return s.c.GetDeviceAllParameters(ctx, s.sn)
 }
+
+// GetStatus returns the current state of the plug
+func (s *SmartPlug) GetStatus(ctx context.Context) (*SmartPlugStatus, error) {
+	params, err := s.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeSmartPlugStatus(params)
+}
+
+// DecodeSmartPlugStatus decodes the plug state from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// The plug reports watts in 0.1 W and current in mA, they are converted to W and A
+func DecodeSmartPlugStatus(params map[string]interface{}) (*SmartPlugStatus, error) {
+	watts, ok := NumericValue(params["2_1.watts"])
+	if !ok {
+		return nil, errors.New("2_1.watts parameter is not found")
+	}
+	value := func(key string) float64 {
+		v, _ := NumericValue(params["2_1."+key])
+		return v
+	}
+	return &SmartPlugStatus{
+		Watts:          watts / 10,
+		Current:        value("current") / 1000,
+		Voltage:        value("volt"),
+		Frequency:      value("freq"),
+		Temperature:    value("temp"),
+		RelayOn:        value("switchSta") == 1,
+		Brightness:     int(value("brightness")),
+		MaxWatts:       int(value("maxWatts")),
+		WattsProtected: value("wattsProtect") == 1,
+		ErrorCode:      int(value("errCode")),
+		WarningCode:    int(value("warnCode")),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package ecoflow
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSmartPlugScheduledTasks(t *testing.T) {
+	client, _ := newTestClient(t, map[string]interface{}{
+		"2_1.switchSta":                      1,
+		"2_2.task1.taskIndex":                1,
+		"2_2.task1.type":                     1,
+		"2_2.task1.timeRange.isEnable":       1,
+		"2_2.task1.timeRange.timeMode":       1,
+		"2_2.task1.timeRange.mode1.sun":      1,
+		"2_2.task1.timeRange.mode1.sat":      1,
+		"2_2.task1.timeRange.startTime.hour": 7,
+		"2_2.task1.timeRange.startTime.min":  30,
+		"2_2.task0.taskIndex":                0,
+		"2_2.task0.type":                     0,
+		"2_2.task0.timeRange.isEnable":       0,
+		"2_2.task0.timeRange.timeMode":       0,
+		"2_2.task0.timeRange.startTime.hour": 22,
+		"2_2.task0.timeRange.startTime.min":  0,
+		"2_2.task0.timeRange.startTime.sec":  0,
+	})
+	plug := client.GetSmartPlug("HW52ZDH1RF3J0033")
+
+	tasks, err := plug.GetScheduledTasks(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []PlugTask{
+		{Index: 0, Enabled: false, Weekdays: EveryDay, Time: 22 * time.Hour, Type: 0},
+		{Index: 1, Enabled: true, Weekdays: NewWeekdays(time.Saturday, time.Sunday), Time: 7*time.Hour + 30*time.Minute, Type: 1},
+	}
+	if !reflect.DeepEqual(tasks, expected) {
+		t.Errorf("got %+v, expected %+v", tasks, expected)
+	}
+
+	// the nested objects of the MQTT messages
+	nested := map[string]interface{}{"param": map[string]interface{}{"task": []interface{}{
+		map[string]interface{}{"taskIndex": 1.0, "type": 1.0, "timeRange": map[string]interface{}{
+			"isEnable": 1.0, "timeMode": 1.0, "mode1": map[string]interface{}{"sun": 1.0, "sat": 1.0},
+			"startTime": map[string]interface{}{"hour": 7.0, "min": 30.0}}},
+	}}}
+	if decoded := DecodePlugTasks(nested); len(decoded) != 1 || decoded[0] != expected[1] {
+		t.Errorf("got %+v, expected %+v", decoded, expected[1])
+	}
+}
+
+func TestSmartPlugStatus(t *testing.T) {
+	client, _ := newTestClient(t, map[string]interface{}{
+		"2_1.watts":     1234,
+		"2_1.current":   5600,
+		"2_1.volt":      230,
+		"2_1.temp":      31,
+		"2_1.switchSta": true,
+		"2_1.maxWatts":  2500,
+		"2_1.errCode":   0,
+	})
+	plug := client.GetSmartPlug("HW52ZDH1RF3J0033")
+	ctx := context.Background()
+
+	status, err := plug.GetStatus(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := SmartPlugStatus{Watts: 123.4, Current: 5.6, Voltage: 230, Temperature: 31, RelayOn: true, MaxWatts: 2500}
+	if *status != expected {
+		t.Errorf("got %+v, expected %+v", *status, expected)
+	}
+}
@@ -0,0 +1,181 @@
+package ecoflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DeviceSnapshot is a set of device parameters (quotas) received at some point of time either from the REST API
+// (all parameters) or from the MQTT topic (usually only changed parameters, see Partial)
+type DeviceSnapshot struct {
+	Sn         string
+	DeviceType DeviceType
+	Timestamp  time.Time
+	// Partial is true when Params contain only a subset of the device parameters, e.g. the ones received via MQTT
+	Partial bool
+	Params  map[string]interface{}
+}
+
+// SplitQuotaKey splits a parameter name into the module and the field, e.g. "bms_bmsStatus.soc" -> "bms_bmsStatus", "soc".
+// If the key doesn't contain a module, the module is an empty string
+func SplitQuotaKey(key string) (module, field string) {
+	if i := strings.Index(key, "."); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+// requireParameters returns an error if one of the parameters is not reported or is not a number. It's used by the
+// setters that send the current values of the other settings, so a missing value is never sent as 0
+func requireParameters(params map[string]interface{}, keys ...string) error {
+	for _, key := range keys {
+		if _, ok := NumericValue(params[key]); !ok {
+			return fmt.Errorf("%s parameter is not found", key)
+		}
+	}
+	return nil
+}
+
+// NumericValue converts a parameter value to float64. Bool values are converted to 0 or 1.
+// The second returned value is false if the value is not a number (e.g. string, array or nested object)
+func NumericValue(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case bool:
+		if n {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// Time returns the time when the parameters were sent by the device.
+// Ecoflow sends the timestamp in milliseconds, zero time is returned if the timestamp is not present in the message
+func (p *MqttDeviceParams) Time() time.Time {
+	if p.Timestamp == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(p.Timestamp))
+}
+
+// ParseMqttSnapshot converts the MQTT message payload received from the device's topic to a partial DeviceSnapshot.
+// If the message doesn't have the timestamp, the current time is used.
+func ParseMqttSnapshot(deviceSn string, payload []byte) (*DeviceSnapshot, error) {
+	var params MqttDeviceParams
+	if err := json.Unmarshal(payload, &params); err != nil {
+		return nil, err
+	}
+	ts := params.Time()
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	return &DeviceSnapshot{
+		Sn:         deviceSn,
+		DeviceType: InferDeviceType(deviceSn, ""),
+		Timestamp:  ts,
+		Partial:    true,
+		Params:     params.Params,
+	}, nil
+}
+
+// KeyFilter selects parameters by their names using glob patterns (see path.Match), e.g. "pd.*" or "bms_bmsStatus.soc".
+// A key is accepted if it matches any of the Allow patterns (or Allow is empty) and doesn't match any of the Deny patterns
+type KeyFilter struct {
+	Allow []string
+	Deny  []string
+}
+
+// NewKeyFilter creates a KeyFilter and validates the patterns
+func NewKeyFilter(allow, deny []string) (*KeyFilter, error) {
+	for _, p := range append(append([]string{}, allow...), deny...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q: %w", p, err)
+		}
+	}
+	return &KeyFilter{Allow: allow, Deny: deny}, nil
+}
+
+// Match returns true if the key is accepted by the filter. Nil filter accepts all keys
+func (f *KeyFilter) Match(key string) bool {
+	if f == nil {
+		return true
+	}
+	for _, p := range f.Deny {
+		if ok, _ := path.Match(p, key); ok {
+			return false
+		}
+	}
+	if len(f.Allow) == 0 {
+		return true
+	}
+	for _, p := range f.Allow {
+		if ok, _ := path.Match(p, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// keyedObjects groups the parameters of the sub-devices (e.g. battery packs) by their index or serial number.
+// The parameters can be reported as an array ("prefix": [{...}, {...}]), as an object ("prefix": {"0": {...}}),
+// as objects with the key suffix ("prefix.0": {...}) or flattened ("prefix.0.soc")
+func keyedObjects(params map[string]interface{}, prefix string) map[string]map[string]interface{} {
+	objects := make(map[string]map[string]interface{})
+	set := func(key, field string, value interface{}) {
+		if objects[key] == nil {
+			objects[key] = make(map[string]interface{})
+		}
+		objects[key][field] = value
+	}
+	merge := func(key string, m map[string]interface{}) {
+		for field, value := range m {
+			set(key, field, value)
+		}
+	}
+	switch v := params[prefix].(type) {
+	case []interface{}:
+		for i, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				merge(strconv.Itoa(i), m)
+			}
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			if m, ok := item.(map[string]interface{}); ok {
+				merge(k, m)
+			}
+		}
+	}
+	for key, value := range params {
+		rest, found := strings.CutPrefix(key, prefix+".")
+		if !found {
+			continue
+		}
+		id, field, found := strings.Cut(rest, ".")
+		if !found {
+			if m, ok := value.(map[string]interface{}); ok {
+				merge(id, m)
+			}
+			continue
+		}
+		set(id, field, value)
+	}
+	return objects
+}
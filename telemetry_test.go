@@ -0,0 +1,79 @@
+package ecoflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInferDeviceType(t *testing.T) {
+	tests := []struct {
+		name        string
+		sn          string
+		productName string
+		expected    DeviceType
+	}{
+		{"Product name", "XXXX", "DELTA 2", DeviceTypePowerStation},
+		{"Pro product name", "XXXX", "Delta Pro", DeviceTypePowerStationPro},
+		{"Pro 3 product name", "XXXX", "DELTA Pro 3", DeviceTypeDeltaPro3},
+		{"River 3 product name", "XXXX", "RIVER 3 Plus", DeviceTypePowerStation3},
+		{"Delta 3 product name", "XXXX", "DELTA 3 Plus", DeviceTypePowerStation3},
+		{"Pro Ultra serial number", "Y711ZAB59G170001", "", DeviceTypeDeltaProUltra},
+		{"PowerOcean product name", "XXXX", "PowerOcean", DeviceTypePowerOcean},
+		{"Smart Generator product name", "XXXX", "Smart Generator (Dual Fuel)", DeviceTypeSmartGenerator},
+		{"Alternator Charger serial number", "F371ZE1AXH000001", "", DeviceTypeAlternatorCharger},
+		{"Smart Home Panel 2 product name", "XXXX", "Smart Home Panel 2", DeviceTypeSmartHomePanel2},
+		{"Smart Home Panel product name", "XXXX", "Smart Home Panel", DeviceTypeSmartHomePanel},
+		{"Serial number", "R601ZEB4ZEAL0001", "", DeviceTypePowerStation},
+		{"River 3 serial number", "R651ZEB5XH000001", "", DeviceTypePowerStation3},
+		{"Delta 3 Plus serial number", "P351ZAHAPH000001", "", DeviceTypePowerStation3},
+		{"Smart plug serial number", "HW52ZDH1RF3J0033", "", DeviceTypeSmartPlug},
+		{"Unknown", "ZZZZ0000", "", DeviceTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := InferDeviceType(tt.sn, tt.productName); actual != tt.expected {
+				t.Errorf("InferDeviceType(%s, %s) = %s; expected %s", tt.sn, tt.productName, actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestKeyFilter(t *testing.T) {
+	filter, err := NewKeyFilter([]string{"pd.*", "inv.outputWatts"}, []string{"pd.wifi*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := map[string]bool{
+		"pd.soc":          true,
+		"pd.wifiRssi":     false,
+		"inv.outputWatts": true,
+		"inv.inputWatts":  false,
+	}
+	for key, expected := range tests {
+		if actual := filter.Match(key); actual != expected {
+			t.Errorf("Match(%s) = %v; expected %v", key, actual, expected)
+		}
+	}
+
+	if _, err = NewKeyFilter([]string{"pd.["}, nil); err == nil {
+		t.Errorf("expected error for invalid pattern, got nil")
+	}
+}
+
+func TestParseMqttSnapshot(t *testing.T) {
+	payload := []byte(`{"id":1,"timestamp":1700000000123,"moduleType":"1","params":{"pd.soc":55}}`)
+	snapshot, err := ParseMqttSnapshot("R331ZEB4ZEAL0528", payload)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !snapshot.Timestamp.Equal(time.UnixMilli(1700000000123)) {
+		t.Errorf("unexpected timestamp %v", snapshot.Timestamp)
+	}
+	if !snapshot.Partial || snapshot.DeviceType != DeviceTypePowerStation {
+		t.Errorf("unexpected snapshot %+v", snapshot)
+	}
+	if v, ok := NumericValue(snapshot.Params["pd.soc"]); !ok || v != 55 {
+		t.Errorf("unexpected pd.soc value %v", snapshot.Params["pd.soc"])
+	}
+}
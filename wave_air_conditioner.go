@@ -2,15 +2,15 @@ package ecoflow
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 )
 
 type WaveAirConditioner struct {
-	c  *Client
-	sn string
+	c    *Client
+	sn   string
+	caps WaveCapabilities
 }
 
 type ConditionerMainMode int
@@ -61,45 +61,84 @@ const (
 	ConditionerPowerModeShutdown ConditionerPowerMode = 3
 )
 
+const (
+	waveMinTemperature = 16
+	waveMaxTemperature = 30
+)
+
+// SetTarget verifies the state reported after the commands, the device reports the new state with a delay
+var (
+	waveVerifyAttempts = 5
+	waveVerifyDelay    = 2 * time.Second
+)
+
+// WaveStatus is the state of a Wave air conditioner decoded from "pd.*" and "bms.*" parameters
+type WaveStatus struct {
+	PowerMode ConditionerPowerMode
+	MainMode  ConditionerMainMode
+	SubMode   ConditionerSubMode
+	Fan       ConditionerWindSpeed
+	Unit      TemperatureUnit
+	// SetTemperature is the target temperature, ℃
+	SetTemperature int
+	// AmbientTemperature is the room temperature, ℃
+	AmbientTemperature float64
+	// Drainage is the automatic drainage setting (wteFthEn), see SetAutomaticDrainage
+	Drainage int
+	// BatterySoc is the battery level, %
+	BatterySoc float64
+}
+
 func (c *WaveAirConditioner) GetSn() string {
 	return c.sn
 }
 
-// SetMainMode Set main mode(0: Cool, 1: Heat, 2: Fan)
+// Capabilities returns the modes and the temperature range of the model, the setters reject the unsupported values
+func (c *WaveAirConditioner) Capabilities() WaveCapabilities {
+	return c.caps
+}
+
+// SetMainMode Set main mode(0: Cool, 1: Heat, 2: Fan), the mode must be supported by the model
 // { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "moduleType":1, "operateType":"mainMode", "params":{ "mainMode":1 } }
 func (c *WaveAirConditioner) SetMainMode(ctx context.Context, mainMode ConditionerMainMode) (*CmdSetResponse, error) {
+	if err := c.caps.validateMainMode(mainMode); err != nil {
+		return nil, err
+	}
 	params := make(map[string]interface{})
 	params["mainMode"] = mainMode
-	return c.setParameter(ctx, "mainMode", ModuleTypePd, params)
+	return c.setParameter(ctx, "mainMode", params)
 }
 
 // SetSubMode Set sub-mode(0: Max, 1: Sleep, 2: Eco, 3: Manual)
-// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "operateType":"subMode", "params":{ "subMode":3 } }
+// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "moduleType":1, "operateType":"subMode", "params":{ "subMode":3 } }
 func (c *WaveAirConditioner) SetSubMode(ctx context.Context, subMode ConditionerSubMode) (*CmdSetResponse, error) {
+	if subMode < ConditionerSubModeMax || subMode > ConditionerSubModeManual {
+		return nil, errors.New("subMode out of range. Range 0:3")
+	}
 	params := make(map[string]interface{})
 	params["subMode"] = subMode
-	return c.setParameter(ctx, "subMode", -1, params)
+	return c.setParameter(ctx, "subMode", params)
 }
 
 // SetTemperatureUnit Set unit of temperature(0: Celsius, 1: Fahrenheit)
-// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "operateType":"tempSys", "params":{ "mode":1 } }
+// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "moduleType":1, "operateType":"tempSys", "params":{ "mode":1 } }
 func (c *WaveAirConditioner) SetTemperatureUnit(ctx context.Context, mode TemperatureUnit) (*CmdSetResponse, error) {
 	params := make(map[string]interface{})
 	params["mode"] = mode
-	return c.setParameter(ctx, "tempSys", -1, params)
+	return c.setParameter(ctx, "tempSys", params)
 }
 
 // SetScreenTimeout Set screen timeout (time unit: sec; Always on: "idleTime": 0, "idleMode": 0)
-// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "operateType":"display", "params":{ "idleTime":5, "idleMode":1 } }
+// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "moduleType":1, "operateType":"display", "params":{ "idleTime":5, "idleMode":1 } }
 func (c *WaveAirConditioner) SetScreenTimeout(ctx context.Context, idleTime int, hasScreenTimeout SettingSwitcher) (*CmdSetResponse, error) {
 	params := make(map[string]interface{})
 	params["idleTime"] = idleTime
 	params["idleMode"] = hasScreenTimeout
-	return c.setParameter(ctx, "display", -1, params)
+	return c.setParameter(ctx, "display", params)
 }
 
 // SetTimer Set timer(timeSet: 0-65535; Unit: min;timeEn: 0: Turn off 1: Turn on)
-// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "operateType":"sacTiming", "params":{ "timeSet":10, "timeEn":1 } }
+// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "moduleType":1, "operateType":"sacTiming", "params":{ "timeSet":10, "timeEn":1 } }
 func (c *WaveAirConditioner) SetTimer(ctx context.Context, timeSet int, timeEn SettingSwitcher) (*CmdSetResponse, error) {
 	if timeSet < 0 || timeSet > 65535 {
 		return nil, errors.New("timeSet is out of range. Range 0:65535")
@@ -107,23 +146,26 @@ func (c *WaveAirConditioner) SetTimer(ctx context.Context, timeSet int, timeEn S
 	params := make(map[string]interface{})
 	params["timeSet"] = timeSet
 	params["timeEn"] = timeEn
-	return c.setParameter(ctx, "sacTiming", -1, params)
+	return c.setParameter(ctx, "sacTiming", params)
 }
 
 // SetEnableBuzzer Enable buzzer (0: Disable; 1: Enable)
-// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "operateType":"beepEn", "params":{ "en":1 } }
+// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "moduleType":1, "operateType":"beepEn", "params":{ "en":1 } }
 func (c *WaveAirConditioner) SetEnableBuzzer(ctx context.Context, enabled SettingSwitcher) (*CmdSetResponse, error) {
 	params := make(map[string]interface{})
 	params["en"] = enabled
-	return c.setParameter(ctx, "beepEn", -1, params)
+	return c.setParameter(ctx, "beepEn", params)
 }
 
 // SetTemperature Set temperature(16-30 ℃）
 // { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "moduleType":1, "operateType":"setTemp", "params":{ "setTemp":27 } }
 func (c *WaveAirConditioner) SetTemperature(ctx context.Context, setTemp int) (*CmdSetResponse, error) {
+	if err := validateWaveTemperature(setTemp); err != nil {
+		return nil, err
+	}
 	params := make(map[string]interface{})
 	params["setTemp"] = setTemp
-	return c.setParameter(ctx, "setTemp", ModuleTypePd, params)
+	return c.setParameter(ctx, "setTemp", params)
 }
 
 // SetTemperatureDisplay Set temperature display (0: Display ambient temperature; 1: Display air outlet temperature)
@@ -131,21 +173,21 @@ func (c *WaveAirConditioner) SetTemperature(ctx context.Context, setTemp int) (*
 func (c *WaveAirConditioner) SetTemperatureDisplay(ctx context.Context, tempDisplay ConditionerTemperatureDisplayMode) (*CmdSetResponse, error) {
 	params := make(map[string]interface{})
 	params["tempDisplay"] = tempDisplay
-	return c.setParameter(ctx, "setTemp", ModuleTypePd, params)
+	return c.setParameter(ctx, "tempDisplay", params)
 }
 
 // SetWindSpeed Set wind speed (0: Low; 1: Medium; 2: High)
-// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "operateType":"fanValue", "params":{ "fanValue":1 } }
+// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "moduleType":1, "operateType":"fanValue", "params":{ "fanValue":1 } }
 func (c *WaveAirConditioner) SetWindSpeed(ctx context.Context, fanValue ConditionerWindSpeed) (*CmdSetResponse, error) {
 	params := make(map[string]interface{})
 	params["fanValue"] = fanValue
-	return c.setParameter(ctx, "fanValue", -1, params)
+	return c.setParameter(ctx, "fanValue", params)
 }
 
 // SetAutomaticDrainage Set automatic drainage(
 // In Cool/Fan mode: 0: Turn on Manual drainage，1: Turn on No drainage, 2: Turn off Manual drainage, 3 Turn off No drainage
 // In Heat Mode: 0: Turn off, 1: Turn on Manual drainage， 3: Turn off Manual drainage)
-// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "operateType":"wteFthEn", "params":{ "wteFthEn":3 } }
+// { "id":123456789, "version":"1.0", "sn":"KT21ZCH2ZF170012", "moduleType":1, "operateType":"wteFthEn", "params":{ "wteFthEn":3 } }
 func (c *WaveAirConditioner) SetAutomaticDrainage(ctx context.Context, wteFthEn int) (*CmdSetResponse, error) {
 	if wteFthEn < 0 || wteFthEn > 3 {
 		return nil, errors.New("wteFthEn is out of range. Range 0:3")
@@ -153,7 +195,7 @@ func (c *WaveAirConditioner) SetAutomaticDrainage(ctx context.Context, wteFthEn
 	params := make(map[string]interface{})
 	params["wteFthEn"] = wteFthEn
 
-	return c.setParameter(ctx, "wteFthEn", -1, params)
+	return c.setParameter(ctx, "wteFthEn", params)
 }
 
 // SetLightStripMode Light strip settings (0: Follow the screen; 1: Always on; 2: Always off; other parameters indicate “Always off”)
@@ -162,7 +204,7 @@ func (c *WaveAirConditioner) SetLightStripMode(ctx context.Context, rgbState Con
 	params := make(map[string]interface{})
 	params["rgbState"] = rgbState
 
-	return c.setParameter(ctx, "rgbState", ModuleTypePd, params)
+	return c.setParameter(ctx, "rgbState", params)
 }
 
 // SetPowerMode Remote startup/shutdown (1: Startup; 2: Standby; 3: Shutdown)
@@ -171,39 +213,176 @@ func (c *WaveAirConditioner) SetPowerMode(ctx context.Context, powerMode Conditi
 	params := make(map[string]interface{})
 	params["powerMode"] = powerMode
 
-	return c.setParameter(ctx, "powerMode", ModuleTypePd, params)
+	return c.setParameter(ctx, "powerMode", params)
 }
 
-func (c *WaveAirConditioner) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
-	return c.c.GetDeviceParameters(ctx, c.sn, params)
+// GetStatus returns the typed state of the air conditioner
+func (c *WaveAirConditioner) GetStatus(ctx context.Context) (*WaveStatus, error) {
+	params, err := c.GetAllParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeWaveStatus(params)
 }
 
-func (c *WaveAirConditioner) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
-	return c.c.GetDeviceAllParameters(ctx, c.sn)
+// DecodeWaveStatus decodes the air conditioner state from the parameters (see GetAllParameters or DeviceSnapshot.Params).
+// Missing parameters have zero values, an error is returned only if the main mode is not found
+func DecodeWaveStatus(params map[string]interface{}) (*WaveStatus, error) {
+	mainMode, ok := NumericValue(params["pd.mainMode"])
+	if !ok {
+		return nil, errors.New("pd.mainMode parameter is not found")
+	}
+	value := func(key string) float64 {
+		v, _ := NumericValue(params[key])
+		return v
+	}
+	return &WaveStatus{
+		PowerMode:          ConditionerPowerMode(value("pd.powerMode")),
+		MainMode:           ConditionerMainMode(mainMode),
+		SubMode:            ConditionerSubMode(value("pd.subMode")),
+		Fan:                ConditionerWindSpeed(value("pd.fanValue")),
+		Unit:               TemperatureUnit(value("pd.tempSys")),
+		SetTemperature:     int(value("pd.setTemp")),
+		AmbientTemperature: value("pd.envTemp"),
+		Drainage:           int(value("pd.wteFthEn")),
+		BatterySoc:         value("bms.soc"),
+	}, nil
 }
 
-func (c *WaveAirConditioner) setParameter(ctx context.Context, opType string, modType ModuleType, params map[string]interface{}) (*CmdSetResponse, error) {
-	cmdReq := CmdSetRequest{
-		Id:          fmt.Sprint(time.Now().UnixMilli()),
-		OperateType: opType,
-		Sn:          c.sn,
-		Params:      params,
+// SetTarget switches the air conditioner on and sets the mode, the temperature (16-30 ℃, not used in the Fan mode)
+// and the fan speed like a thermostat. The commands are sent in this order and only if the setting differs from the
+// current state: the temperature is set after the mode because it's stored per mode. After the commands the reported
+// state is checked, the verified state is returned. In the dry-run mode the state isn't checked, the expected state is
+// returned and the requests are returned by DryRunRequests
+func (c *WaveAirConditioner) SetTarget(ctx context.Context, mode ConditionerMainMode, temp int, fan ConditionerWindSpeed) (*WaveStatus, error) {
+	if err := c.caps.validateMainMode(mode); err != nil {
+		return nil, err
 	}
-
-	if modType != -1 {
-		cmdReq.ModuleType = modType
+	if modeUsesTemperature(mode) {
+		if err := validateWaveTemperature(temp); err != nil {
+			return nil, err
+		}
+	}
+	if fan < ConditionerWindSpeedLow || fan > ConditionerWindSpeedHigh {
+		return nil, errors.New("fanValue is out of range. Range 0:2")
 	}
 
-	jsonData, err := json.Marshal(cmdReq)
+	status, err := c.targetState(ctx)
 	if err != nil {
 		return nil, err
 	}
+	commands := []struct {
+		required bool
+		send     func() (*CmdSetResponse, error)
+	}{
+		{status.PowerMode != ConditionerPowerModeStartup, func() (*CmdSetResponse, error) { return c.SetPowerMode(ctx, ConditionerPowerModeStartup) }},
+		{status.MainMode != mode, func() (*CmdSetResponse, error) { return c.SetMainMode(ctx, mode) }},
+		{modeUsesTemperature(mode) && status.SetTemperature != temp, func() (*CmdSetResponse, error) { return c.SetTemperature(ctx, temp) }},
+		{status.Fan != fan, func() (*CmdSetResponse, error) { return c.SetWindSpeed(ctx, fan) }},
+	}
+	sent := false
+	for _, cmd := range commands {
+		if !cmd.required {
+			continue
+		}
+		if err = checkCmdResponse(cmd.send()); err != nil {
+			return nil, err
+		}
+		sent = true
+	}
+	if !sent {
+		return status, nil
+	}
+	if c.c.isDryRun(ctx) {
+		// the commands weren't sent, the state can't be verified
+		return targetStatus(status, mode, temp, fan), nil
+	}
 
-	var req map[string]interface{}
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(waveVerifyDelay):
+		}
+		if status, err = c.targetState(ctx); err != nil {
+			return nil, err
+		}
+		mismatch := targetMismatch(status, mode, temp, fan)
+		if mismatch == "" {
+			return status, nil
+		}
+		if attempt == waveVerifyAttempts {
+			return status, fmt.Errorf("the air conditioner didn't apply the target, %s", mismatch)
+		}
+	}
+}
 
-	err = json.Unmarshal(jsonData, &req)
+// targetState reads the state compared with the target by SetTarget. The compared parameters must be reported:
+// a missing value is decoded as 0, which is a valid mode, temperature or fan speed
+func (c *WaveAirConditioner) targetState(ctx context.Context) (*WaveStatus, error) {
+	params, err := c.GetAllParameters(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return c.c.SetDeviceParameter(ctx, req)
+	if err = requireParameters(params, "pd.powerMode", "pd.mainMode", "pd.setTemp", "pd.fanValue"); err != nil {
+		return nil, err
+	}
+	return DecodeWaveStatus(params)
+}
+
+// targetStatus returns the state with the target applied, it's the state expected after SetTarget
+func targetStatus(status *WaveStatus, mode ConditionerMainMode, temp int, fan ConditionerWindSpeed) *WaveStatus {
+	target := *status
+	target.PowerMode = ConditionerPowerModeStartup
+	target.MainMode = mode
+	if modeUsesTemperature(mode) {
+		target.SetTemperature = temp
+	}
+	target.Fan = fan
+	return &target
+}
+
+// targetMismatch describes the first difference between the reported state and the target, empty if there is none
+func targetMismatch(status *WaveStatus, mode ConditionerMainMode, temp int, fan ConditionerWindSpeed) string {
+	switch {
+	case status.PowerMode != ConditionerPowerModeStartup:
+		return fmt.Sprintf("powerMode is %d", status.PowerMode)
+	case status.MainMode != mode:
+		return fmt.Sprintf("mainMode is %d, expected %d", status.MainMode, mode)
+	case modeUsesTemperature(mode) && status.SetTemperature != temp:
+		return fmt.Sprintf("setTemp is %d, expected %d", status.SetTemperature, temp)
+	case status.Fan != fan:
+		return fmt.Sprintf("fanValue is %d, expected %d", status.Fan, fan)
+	}
+	return ""
+}
+
+func validateWaveTemperature(temp int) error {
+	if temp < waveMinTemperature || temp > waveMaxTemperature {
+		return fmt.Errorf("setTemp is out of range. Range %d:%d", waveMinTemperature, waveMaxTemperature)
+	}
+	return nil
+}
+
+// modeUsesTemperature returns false for the modes that ignore the target temperature (Fan)
+func modeUsesTemperature(mode ConditionerMainMode) bool {
+	return mode == ConditionerMainModeCool || mode == ConditionerMainModeHeat
+}
+
+func (c *WaveAirConditioner) GetParameter(ctx context.Context, params []string) (*GetCmdResponse, error) {
+	return c.c.GetDeviceParameters(ctx, c.sn, params)
+}
+
+func (c *WaveAirConditioner) GetAllParameters(ctx context.Context) (map[string]interface{}, error) {
+	return c.c.GetDeviceAllParameters(ctx, c.sn)
+}
+
+// all the commands are sent to the PD module (moduleType 1)
+func (c *WaveAirConditioner) setParameter(ctx context.Context, opType string, params map[string]interface{}) (*CmdSetResponse, error) {
+	return c.c.SendCommand(ctx, Command{
+		Sn:          c.sn,
+		OperateType: opType,
+		ModuleType:  ModuleTypePd,
+		Params:      params,
+	})
 }
@@ -0,0 +1,213 @@
+package ecoflow
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newWaveTestServer returns an air conditioner connected to a test server that applies the commands to the quota,
+// unless the command is ignored by the "device". The operateTypes of the sent commands are stored in operations
+func newWaveTestServer(t *testing.T, quota map[string]interface{}, ignored string, operations *[]string) *WaveAirConditioner {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			body, _ := io.ReadAll(r.Body)
+			var req struct {
+				OperateType string                 `json:"operateType"`
+				ModuleType  int                    `json:"moduleType"`
+				Params      map[string]interface{} `json:"params"`
+			}
+			if err := json.Unmarshal(body, &req); err != nil || req.ModuleType != int(ModuleTypePd) {
+				t.Errorf("invalid request body: %s", body)
+			}
+			*operations = append(*operations, req.OperateType)
+			if req.OperateType != ignored {
+				for k, v := range req.Params {
+					quota["pd."+k] = v
+				}
+			}
+			_, _ = w.Write([]byte(`{"code":"0","message":"Success"}`))
+			return
+		}
+		response, _ := json.Marshal(map[string]interface{}{"code": "0", "message": "Success", "data": quota})
+		_, _ = w.Write(response)
+	}))
+	t.Cleanup(server.Close)
+	return NewEcoflowClient("access", "secret", WithBaseUrl(server.URL), WithHttpClient(server.Client())).GetWaveAirConditioner("KT21ZCH2ZF170012")
+}
+
+func TestWaveSetTarget(t *testing.T) {
+	waveVerifyDelay = time.Millisecond
+	standby := func() map[string]interface{} {
+		return map[string]interface{}{"pd.powerMode": 2, "pd.mainMode": 1, "pd.setTemp": 22, "pd.fanValue": 1, "pd.envTemp": 27, "bms.soc": 64}
+	}
+	running := func() map[string]interface{} {
+		return map[string]interface{}{"pd.powerMode": 1, "pd.mainMode": 0, "pd.setTemp": 24, "pd.fanValue": 2}
+	}
+	missingFan := running()
+	delete(missingFan, "pd.fanValue")
+
+	tests := []struct {
+		name       string
+		quota      map[string]interface{}
+		ignored    string
+		mode       ConditionerMainMode
+		temp       int
+		fan        ConditionerWindSpeed
+		operations []string
+		expected   *WaveStatus
+		wantErr    bool
+	}{
+		{
+			name:       "All settings in order",
+			quota:      standby(),
+			mode:       ConditionerMainModeCool,
+			temp:       24,
+			fan:        ConditionerWindSpeedHigh,
+			operations: []string{"powerMode", "mainMode", "setTemp", "fanValue"},
+			expected: &WaveStatus{PowerMode: ConditionerPowerModeStartup, MainMode: ConditionerMainModeCool, Fan: ConditionerWindSpeedHigh,
+				SetTemperature: 24, AmbientTemperature: 27, BatterySoc: 64},
+		},
+		{
+			name:     "Target already applied",
+			quota:    running(),
+			mode:     ConditionerMainModeCool,
+			temp:     24,
+			fan:      ConditionerWindSpeedHigh,
+			expected: &WaveStatus{PowerMode: ConditionerPowerModeStartup, MainMode: ConditionerMainModeCool, Fan: ConditionerWindSpeedHigh, SetTemperature: 24},
+		},
+		{
+			// the temperature isn't sent in the Fan mode
+			name:       "Fan command ignored by the device",
+			quota:      running(),
+			ignored:    "fanValue",
+			mode:       ConditionerMainModeFan,
+			fan:        ConditionerWindSpeedLow,
+			operations: []string{"mainMode", "fanValue"},
+			wantErr:    true,
+		},
+		{
+			name:    "Temperature out of range",
+			quota:   running(),
+			mode:    ConditionerMainModeHeat,
+			temp:    31,
+			wantErr: true,
+		},
+		{
+			// a missing fan speed would be decoded as Low and the fan command wouldn't be sent
+			name:    "Fan speed not reported",
+			quota:   missingFan,
+			mode:    ConditionerMainModeCool,
+			temp:    24,
+			fan:     ConditionerWindSpeedLow,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []string
+			wave := newWaveTestServer(t, tt.quota, tt.ignored, &operations)
+			status, err := wave.SetTarget(context.Background(), tt.mode, tt.temp, tt.fan)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("got error %v, expected error: %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(operations, tt.operations) {
+				t.Errorf("got commands %v, expected %v", operations, tt.operations)
+			}
+			if tt.expected != nil && *status != *tt.expected {
+				t.Errorf("got %+v, expected %+v", *status, *tt.expected)
+			}
+		})
+	}
+}
+
+func TestWaveSetters(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{"pd.mainMode": 0})
+	wave := client.GetWaveAirConditioner("KT21ZCH2ZF170012")
+
+	runSetterTests(t, last, []setterTest{
+		{
+			name: "Main mode",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return wave.SetMainMode(ctx, ConditionerMainModeHeat)
+			},
+			operateType: "mainMode",
+			params:      map[string]interface{}{"mainMode": float64(1)},
+		},
+		{
+			name: "Sub-mode",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return wave.SetSubMode(ctx, ConditionerSubModeManual)
+			},
+			operateType: "subMode",
+			params:      map[string]interface{}{"subMode": float64(3)},
+		},
+		{
+			name:        "Temperature",
+			call:        func(ctx context.Context) (*CmdSetResponse, error) { return wave.SetTemperature(ctx, 24) },
+			operateType: "setTemp",
+			params:      map[string]interface{}{"setTemp": float64(24)},
+		},
+		{
+			name: "Sub-mode out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return wave.SetSubMode(ctx, 4) },
+		},
+		{
+			name: "Temperature out of range",
+			call: func(ctx context.Context) (*CmdSetResponse, error) { return wave.SetTemperature(ctx, 15) },
+		},
+	})
+}
+
+func TestWaveModels(t *testing.T) {
+	client, last := newTestClient(t, map[string]interface{}{"pd.mainMode": 0})
+	if _, err := client.GetWaveAirConditionerModel("KT21ZCH2ZF170012", "Wave 4"); err == nil {
+		t.Errorf("expected error for unknown model")
+	}
+	wave, err := client.GetWaveAirConditionerModel("KT11ZCH2ZF170012", WaveModelWave)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Wave 3 has the documented modes of Wave 2
+	wave3, err := client.GetWaveAirConditionerModel("KT31ZCH2ZF170012", WaveModelWave3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	runSetterTests(t, last, []setterTest{
+		{
+			name: "Heat mode of Wave",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return wave.SetMainMode(ctx, ConditionerMainModeHeat)
+			},
+		},
+		{
+			name: "Heat target of Wave",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				_, err := wave.SetTarget(ctx, ConditionerMainModeHeat, 22, ConditionerWindSpeedLow)
+				return nil, err
+			},
+		},
+		{
+			name: "Heat mode of Wave 3",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return wave3.SetMainMode(ctx, ConditionerMainModeHeat)
+			},
+			operateType: "mainMode",
+			params:      map[string]interface{}{"mainMode": float64(1)},
+		},
+		{
+			name: "Undocumented mainMode 3",
+			call: func(ctx context.Context) (*CmdSetResponse, error) {
+				return wave3.SetMainMode(ctx, ConditionerMainMode(3))
+			},
+		},
+	})
+}
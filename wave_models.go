@@ -0,0 +1,59 @@
+package ecoflow
+
+import (
+	"fmt"
+	"slices"
+)
+
+// WaveModel is a model of the Wave air conditioner. The models share the API of WaveAirConditioner,
+// but support different modes
+type WaveModel string
+
+const (
+	WaveModelWave  WaveModel = "Wave"
+	WaveModelWave2 WaveModel = "Wave 2"
+	WaveModelWave3 WaveModel = "Wave 3"
+)
+
+// WaveCapabilities describes the main modes supported by a Wave model. The sub-modes and the target temperature
+// range (16-30 ℃) are the same for all models
+type WaveCapabilities struct {
+	Model     WaveModel
+	MainModes []ConditionerMainMode
+}
+
+var waveCapabilities = map[WaveModel]WaveCapabilities{
+	// the original Wave only cools
+	WaveModelWave: {
+		Model:     WaveModelWave,
+		MainModes: []ConditionerMainMode{ConditionerMainModeCool, ConditionerMainModeFan},
+	},
+	// Wave 2 is the model of the Ecoflow documentation, it's used by GetWaveAirConditioner
+	WaveModelWave2: {
+		Model:     WaveModelWave2,
+		MainModes: []ConditionerMainMode{ConditionerMainModeCool, ConditionerMainModeHeat, ConditionerMainModeFan},
+	},
+	// the dehumidification mode of Wave 3 is not a documented mainMode value, so it's not supported here
+	WaveModelWave3: {
+		Model:     WaveModelWave3,
+		MainModes: []ConditionerMainMode{ConditionerMainModeCool, ConditionerMainModeHeat, ConditionerMainModeFan},
+	},
+}
+
+// GetWaveCapabilities returns the capabilities of the model, false if the model is unknown
+func GetWaveCapabilities(model WaveModel) (WaveCapabilities, bool) {
+	caps, ok := waveCapabilities[model]
+	return caps, ok
+}
+
+// SupportsMainMode returns true if the model supports the main mode
+func (w WaveCapabilities) SupportsMainMode(mode ConditionerMainMode) bool {
+	return slices.Contains(w.MainModes, mode)
+}
+
+func (w WaveCapabilities) validateMainMode(mode ConditionerMainMode) error {
+	if !w.SupportsMainMode(mode) {
+		return fmt.Errorf("mainMode %d is not supported by %s", mode, w.Model)
+	}
+	return nil
+}
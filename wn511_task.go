@@ -0,0 +1,110 @@
+package ecoflow
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Smart Plug and PowerStream (WN511 devices) share the format of the scheduled tasks.
+// The tasks can only be read and deleted: the Open API doesn't document a command that creates or changes a task
+
+const (
+	wn511TimeModeDaily  = 0
+	wn511TimeModeWeekly = 1
+)
+
+// PlugTask is a scheduled task of a Smart Plug or a PowerStream micro inverter as reported by the device
+type PlugTask struct {
+	// Index is the task slot ("taskIndex"), it's used by the delete commands
+	Index int
+	// Enabled is the "timeRange.isEnable" flag
+	Enabled bool
+	// Weekdays when the task runs
+	Weekdays Weekdays
+	// Time is the start time of the task, e.g. 7*time.Hour + 30*time.Minute
+	Time time.Duration
+	// Type is the raw "type" value of the task, its values are not documented
+	Type int
+}
+
+// DecodePlugTasks finds the scheduled tasks in the Smart Plug or PowerStream parameters (see GetAllParameters).
+// A task is an object with "taskIndex" and "timeRange" fields. The REST API returns the task fields as flattened keys,
+// e.g. "2_2.task1.taskIndex" and "2_2.task1.timeRange.startTime.hour", the keys are grouped back into the objects.
+// The nested objects and arrays of objects (MQTT messages) are decoded as well
+func DecodePlugTasks(params map[string]interface{}) []PlugTask {
+	byIndex := make(map[int]PlugTask)
+	var decode func(v interface{})
+	decode = func(v interface{}) {
+		if list, ok := v.([]interface{}); ok {
+			for _, item := range list {
+				decode(item)
+			}
+			return
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return
+		}
+		index, ok := mapInt(m, "taskIndex")
+		timeRange, isMap := m["timeRange"].(map[string]interface{})
+		if !ok || !isMap {
+			for _, child := range m {
+				decode(child)
+			}
+			return
+		}
+		task := PlugTask{Index: index}
+		enabled, _ := mapInt(timeRange, "isEnable")
+		task.Enabled = enabled == 1
+		if timeMode, _ := mapInt(timeRange, "timeMode"); timeMode == wn511TimeModeDaily {
+			task.Weekdays = EveryDay
+		} else {
+			task.Weekdays = weekdaysFromMode1(timeRange["mode1"])
+		}
+		startTime, _ := timeRange["startTime"].(map[string]interface{})
+		hour, _ := mapInt(startTime, "hour")
+		minute, _ := mapInt(startTime, "min")
+		task.Time = time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute
+		task.Type, _ = mapInt(m, "type")
+		byIndex[task.Index] = task
+	}
+
+	decode(unflattenParams(params))
+	tasks := make([]PlugTask, 0, len(byIndex))
+	for _, task := range byIndex {
+		tasks = append(tasks, task)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Index < tasks[j].Index })
+	return tasks
+}
+
+// unflattenParams groups the dotted keys into the nested objects, e.g. {"a.b.c": 1} becomes {"a": {"b": {"c": 1}}}.
+// The values that are objects already are merged with the flattened keys of the same object
+func unflattenParams(params map[string]interface{}) map[string]interface{} {
+	root := make(map[string]interface{})
+	for key, v := range params {
+		m := root
+		parts := strings.Split(key, ".")
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := m[part].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				m[part] = child
+			}
+			m = child
+		}
+		last := parts[len(parts)-1]
+		if nested, ok := v.(map[string]interface{}); ok {
+			if existing, ok := m[last].(map[string]interface{}); ok {
+				for k, nv := range unflattenParams(nested) {
+					existing[k] = nv
+				}
+				continue
+			}
+			v = unflattenParams(nested)
+		}
+		m[last] = v
+	}
+	return root
+}
@@ -0,0 +1,39 @@
+// Package yamlschema parses the YAML schemas of the generic devices (see ecoflow.DeviceSchema).
+// It's a separate module, so the library doesn't depend on a YAML parser:
+//
+//	schema, err := yamlschema.Load("generator.yaml")
+//	device := client.GetGenericDevice(sn, schema)
+package yamlschema
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tess1o/go-ecoflow"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads the schema from the YAML file, the files with .json extension are parsed as JSON
+func Load(path string) (*ecoflow.DeviceSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return ecoflow.ParseDeviceSchemaJSON(data)
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates the YAML schema
+func Parse(data []byte) (*ecoflow.DeviceSchema, error) {
+	var schema ecoflow.DeviceSchema
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
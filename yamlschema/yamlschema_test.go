@@ -0,0 +1,69 @@
+package yamlschema
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testDeviceSchema = `
+name: Test device
+commands:
+  - name: ecoMode
+    operateType: ecoMode
+    moduleType: 1
+    params:
+      - { name: ecoMode, type: int, enum: [0, 1] }
+  - name: backup
+    cmdSet: 32
+    id: 94
+    fixed: { isConfig: 1 }
+    params:
+      - { name: bpPowerSoc, type: float, min: 5, max: 100 }
+telemetry:
+  - { name: soc, key: pd.soc, type: int }
+  - { name: voltage, key: bms.vol, scale: 0.001, unit: V }
+`
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "device.yaml")
+	if err := os.WriteFile(path, []byte(testDeviceSchema), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schema.Name != "Test device" || len(schema.Commands) != 2 || len(schema.Telemetry) != 2 {
+		t.Fatalf("unexpected schema %+v", schema)
+	}
+	if *schema.Commands[1].CmdSet != 32 || schema.Commands[1].Fixed["isConfig"] != 1 || schema.Telemetry[1].Scale != 0.001 {
+		t.Errorf("unexpected schema %+v", schema)
+	}
+
+	path = filepath.Join(t.TempDir(), "device.json")
+	data := `{"name": "Test", "commands": [{"name": "beep", "cmdFunc": 254, "params": [{"name": "cfgBeepEn", "type": "bool"}]}]}`
+	if err = os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if schema, err = Load(path); err != nil || len(schema.Commands) != 1 {
+		t.Errorf("unexpected schema %+v: %v", schema, err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	invalid := []string{
+		`commands: [{name: a}]`,
+		`commands: [{name: a, operateType: x, cmdCode: y}]`,
+		`commands: [{name: a, cmdSet: 11}]`,
+		`commands: [{name: a, cmdCode: y, params: [{name: p, type: number}]}]`,
+		`commands: [{name: a, cmdCode: y}, {name: a, cmdCode: z}]`,
+		`telemetry: [{name: soc}]`,
+		`commands: [`,
+	}
+	for _, s := range invalid {
+		if _, err := Parse([]byte(s)); err == nil {
+			t.Errorf("expected error for %s", s)
+		}
+	}
+}
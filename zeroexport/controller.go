@@ -0,0 +1,161 @@
+// Package zeroexport adjusts the output of a PowerStream micro inverter (custom load power, "permanentWatts") to the
+// household load measured by a local meter, so the solar/battery energy is consumed at home and not exported to the grid.
+//
+// The controller reads the grid power from the meter (positive import, negative export) and moves the inverter output
+// towards the value that makes the grid power equal to TargetGridWatts. To avoid flooding the device with commands
+// the change is ignored if it's less than Deadband, limited by RampUpWatts/RampDownWatts per command and the output
+// increase is sent not more often than MinInterval. The output decrease is sent immediately to stop the export.
+package zeroexport
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+const (
+	defaultMaxWatts      = 600
+	defaultDeadband      = 10
+	defaultRampUpWatts   = 100
+	defaultRampDownWatts = 600
+	defaultMinInterval   = 30 * time.Second
+	defaultInterval      = 5 * time.Second
+)
+
+// Inverter is the controlled inverter, *ecoflow.PowerStreamMicroInverter implements it
+type Inverter interface {
+	SetCustomLoadPowerWatts(ctx context.Context, watts float64) (*ecoflow.CmdSetResponse, error)
+}
+
+type Config struct {
+	Meter    Meter
+	Inverter Inverter
+	// MinWatts and MaxWatts limit the inverter output, 0 and 600 W by default
+	MinWatts float64
+	MaxWatts float64
+	// TargetGridWatts is the grid power to keep, a small positive value (e.g. 20 W) keeps a safety margin against export
+	TargetGridWatts float64
+	// Deadband is the minimum output change that is sent to the inverter, 10 W by default
+	Deadband float64
+	// RampUpWatts and RampDownWatts are the maximum output increase and decrease per command, 100 W and 600 W by default.
+	// The output is decreased faster to stop the export as soon as possible
+	RampUpWatts   float64
+	RampDownWatts float64
+	// MinInterval is the minimum time between two commands increasing the output, 30 seconds by default.
+	// The decrease isn't delayed: the inverter would export to the grid until the next command
+	MinInterval time.Duration
+	// Interval is the meter reading interval used by Run, 5 seconds by default
+	Interval time.Duration
+	// InitialWatts is the inverter output when the controller starts. The first computed output is always sent,
+	// so the inverter is synchronized with the controller
+	InitialWatts float64
+}
+
+// Controller is the zero-export control loop. It's not safe for concurrent use
+type Controller struct {
+	config      Config
+	output      float64
+	synced      bool
+	lastCommand time.Time
+	// now is replaced in tests
+	now func() time.Time
+}
+
+func NewController(config Config) (*Controller, error) {
+	if config.Meter == nil || config.Inverter == nil {
+		return nil, errors.New("meter and inverter are mandatory")
+	}
+	if config.MaxWatts == 0 {
+		config.MaxWatts = defaultMaxWatts
+	}
+	if config.MinWatts < 0 || config.MaxWatts > defaultMaxWatts || config.MinWatts >= config.MaxWatts {
+		return nil, errors.New("output limits are out of range. Range 0:600")
+	}
+	if config.Deadband <= 0 {
+		config.Deadband = defaultDeadband
+	}
+	if config.RampUpWatts <= 0 {
+		config.RampUpWatts = defaultRampUpWatts
+	}
+	if config.RampDownWatts <= 0 {
+		config.RampDownWatts = defaultRampDownWatts
+	}
+	if config.MinInterval <= 0 {
+		config.MinInterval = defaultMinInterval
+	}
+	if config.Interval <= 0 {
+		config.Interval = defaultInterval
+	}
+	return &Controller{
+		config: config,
+		output: clamp(config.InitialWatts, config.MinWatts, config.MaxWatts),
+		now:    time.Now,
+	}, nil
+}
+
+// Output returns the last output sent to the inverter (or the initial output)
+func (c *Controller) Output() float64 {
+	return c.output
+}
+
+// Step reads the meter once and sends the new output to the inverter if required.
+// It returns the current output and true if the command was sent
+func (c *Controller) Step(ctx context.Context) (float64, bool, error) {
+	grid, err := c.config.Meter.GridPower(ctx)
+	if err != nil {
+		return c.output, false, err
+	}
+
+	// the household load is covered by the grid and the inverter, the inverter should cover all except the target
+	target := clamp(c.output+grid-c.config.TargetGridWatts, c.config.MinWatts, c.config.MaxWatts)
+	delta := target - c.output
+	if c.synced {
+		if math.Abs(delta) < c.config.Deadband {
+			return c.output, false, nil
+		}
+		if delta > 0 && c.now().Sub(c.lastCommand) < c.config.MinInterval {
+			return c.output, false, nil
+		}
+	}
+	delta = clamp(delta, -c.config.RampDownWatts, c.config.RampUpWatts)
+	output := math.Round(c.output + delta)
+
+	resp, err := c.config.Inverter.SetCustomLoadPowerWatts(ctx, output)
+	if err != nil {
+		return c.output, false, err
+	}
+	if resp != nil && resp.Code != "0" {
+		return c.output, false, errors.New("inverter rejected the command: " + resp.Message)
+	}
+	c.output = output
+	c.synced = true
+	c.lastCommand = c.now()
+	return c.output, true, nil
+}
+
+// Run executes Step every Interval until the context is cancelled. Errors are logged
+func (c *Controller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.config.Interval)
+	defer ticker.Stop()
+	for {
+		output, sent, err := c.Step(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("Zero export step failed", "error", err)
+		} else if sent {
+			slog.Debug("Inverter output changed", "watts", output)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func clamp(v, low, high float64) float64 {
+	return math.Max(low, math.Min(high, v))
+}
@@ -0,0 +1,147 @@
+package zeroexport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+// Meter measures the household grid power in W: positive when the power is imported from the grid,
+// negative when it's exported
+type Meter interface {
+	GridPower(ctx context.Context) (float64, error)
+}
+
+// MeterFunc is an adapter to use a function as a Meter
+type MeterFunc func(ctx context.Context) (float64, error)
+
+func (f MeterFunc) GridPower(ctx context.Context) (float64, error) {
+	return f(ctx)
+}
+
+// JsonMeter reads the grid power from a JSON HTTP endpoint of a local meter,
+// e.g. Shelly 3EM: Url "http://192.168.1.10/status", Field "total_power"
+type JsonMeter struct {
+	Url string
+	// Field is the path to the power value, nested objects and arrays are separated by dots, e.g. "emeters.0.power"
+	Field string
+	// Scale converts the value to W (e.g. 1000 for kW), 1 is used if 0
+	Scale float64
+	// Invert changes the sign of the value for the meters that report the export as positive
+	Invert bool
+	// HttpClient is used to send the requests, http.DefaultClient if nil
+	HttpClient *http.Client
+}
+
+func (m *JsonMeter) GridPower(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.Url, nil)
+	if err != nil {
+		return 0, err
+	}
+	client := m.HttpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("meter request failed|url=%s, statusCode=%s", m.Url, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	var data interface{}
+	if err = json.Unmarshal(body, &data); err != nil {
+		return 0, err
+	}
+	for _, part := range strings.Split(m.Field, ".") {
+		switch v := data.(type) {
+		case map[string]interface{}:
+			data = v[part]
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(v) {
+				return 0, fmt.Errorf("field %s is not found in the meter response", m.Field)
+			}
+			data = v[i]
+		default:
+			return 0, fmt.Errorf("field %s is not found in the meter response", m.Field)
+		}
+	}
+	value, ok := ecoflow.NumericValue(data)
+	if !ok {
+		return 0, fmt.Errorf("field %s is not a number", m.Field)
+	}
+	return m.scale(value), nil
+}
+
+func (m *JsonMeter) scale(value float64) float64 {
+	if m.Scale != 0 {
+		value *= m.Scale
+	}
+	if m.Invert {
+		value = -value
+	}
+	return value
+}
+
+// SnapshotMeter takes the grid power from the device snapshots, e.g. the parameters of a device received via MQTT.
+// Write must be set as the snapshot handler (ecoflow.PollerConfig.OnSnapshot or ecoflow.MqttClient.SubscribeForSnapshots)
+type SnapshotMeter struct {
+	Sn string
+	// Key is the grid power parameter
+	Key string
+	// Scale converts the value to W (e.g. 0.1 for the parameters in 0.1 W), 1 is used if 0
+	Scale float64
+	// MaxAge is the maximum age of the value, older values are not used. 1 minute by default
+	MaxAge time.Duration
+
+	mu      sync.Mutex
+	value   float64
+	updated time.Time
+}
+
+// Write stores the grid power if the snapshot is from the meter device and contains the power parameter
+func (m *SnapshotMeter) Write(snapshot ecoflow.DeviceSnapshot) {
+	if snapshot.Sn != m.Sn {
+		return
+	}
+	value, ok := ecoflow.NumericValue(snapshot.Params[m.Key])
+	if !ok {
+		return
+	}
+	if m.Scale != 0 {
+		value *= m.Scale
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.value = value
+	m.updated = time.Now()
+}
+
+func (m *SnapshotMeter) GridPower(_ context.Context) (float64, error) {
+	maxAge := m.MaxAge
+	if maxAge <= 0 {
+		maxAge = time.Minute
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.updated.IsZero() || time.Since(m.updated) > maxAge {
+		return 0, errors.New("no recent meter value")
+	}
+	return m.value, nil
+}
@@ -0,0 +1,85 @@
+package zeroexport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tess1o/go-ecoflow"
+)
+
+type fakeInverter struct {
+	commands []float64
+}
+
+func (f *fakeInverter) SetCustomLoadPowerWatts(_ context.Context, watts float64) (*ecoflow.CmdSetResponse, error) {
+	f.commands = append(f.commands, watts)
+	return &ecoflow.CmdSetResponse{Code: "0"}, nil
+}
+
+func TestController(t *testing.T) {
+	var grid float64
+	inverter := &fakeInverter{}
+	c, err := NewController(Config{
+		Meter:    MeterFunc(func(context.Context) (float64, error) { return grid, nil }),
+		Inverter: inverter,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	c.now = func() time.Time { return now }
+
+	steps := []struct {
+		name   string
+		after  time.Duration
+		grid   float64
+		output float64
+		sent   bool
+	}{
+		{"first step is always sent, ramp up is limited", 0, 250, 100, true},
+		{"minimum interval between commands", 10 * time.Second, 150, 100, false},
+		{"ramp up continues", 21 * time.Second, 150, 200, true},
+		{"deadband", 40 * time.Second, 5, 200, false},
+		{"export is stopped", 40 * time.Second, -300, 0, true},
+		{"ramp up after the load increase", 40 * time.Second, 150, 100, true},
+		{"the decrease isn't delayed by the minimum interval", 5 * time.Second, -60, 40, true},
+	}
+	for _, s := range steps {
+		now = now.Add(s.after)
+		grid = s.grid
+		output, sent, err := c.Step(context.Background())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", s.name, err)
+		}
+		if output != s.output || sent != s.sent {
+			t.Errorf("%s: got output %v sent %v, expected %v %v", s.name, output, sent, s.output, s.sent)
+		}
+	}
+	if len(inverter.commands) != 5 {
+		t.Errorf("expected 5 commands, got %v", inverter.commands)
+	}
+}
+
+func TestJsonMeter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"emeters": [{"power": 12.5}, {"power": -40}], "total_power": -27.5}`))
+	}))
+	defer server.Close()
+
+	m := &JsonMeter{Url: server.URL, Field: "emeters.1.power", HttpClient: server.Client()}
+	if v, err := m.GridPower(context.Background()); err != nil || v != -40 {
+		t.Errorf("got %v %v, expected -40", v, err)
+	}
+	m.Field = "total_power"
+	m.Invert = true
+	if v, err := m.GridPower(context.Background()); err != nil || v != 27.5 {
+		t.Errorf("got %v %v, expected 27.5", v, err)
+	}
+	m.Field = "emeters.5.power"
+	if _, err := m.GridPower(context.Background()); err == nil {
+		t.Errorf("expected error for missing field")
+	}
+}